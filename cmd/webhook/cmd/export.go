@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/zonefile"
+)
+
+const (
+	exportFormatBind = "bind"
+	exportFormatJSON = "json"
+)
+
+var (
+	exportZones     []string
+	exportFormat    string
+	exportOutputDir string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "exports zones as zone files",
+	Long: "Exports the zones of the STACKIT projects given by '--project-id' with all of their record sets, e.g. for " +
+		"backups or to migrate them to another DNS provider. The zones are written as BIND zone files (RFC 1035) or " +
+		"as JSON to stdout, or to one file per zone in '--output-dir'.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportFormat != exportFormatBind && exportFormat != exportFormatJSON {
+			return fmt.Errorf("unsupported format %q, supported formats: [%s, %s]", exportFormat, exportFormatBind, exportFormatJSON)
+		}
+
+		logger := getCommandLogger()
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+
+		stackitProvider, err := newCommandProvider(ctx, logger)
+		if err != nil {
+			return err
+		}
+
+		zones, err := stackitProvider.ExportZones(ctx, exportZones)
+		if err != nil {
+			return err
+		}
+
+		if exportOutputDir == "" {
+			return writeZones(cmd.OutOrStdout(), zones)
+		}
+
+		for _, zone := range zones {
+			var buf bytes.Buffer
+			if err := writeZones(&buf, []zonefile.Zone{zone}); err != nil {
+				return err
+			}
+
+			path := filepath.Join(exportOutputDir, exportFileName(zone))
+			if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+				return err
+			}
+
+			logger.Info("exported zone", zap.String("zone", zone.Origin), zap.String("file", path))
+		}
+
+		return nil
+	},
+}
+
+// writeZones writes the zones in the format given by '--format'. Zone files are separated by an empty line, JSON is
+// written as a single array.
+func writeZones(w io.Writer, zones []zonefile.Zone) error {
+	if exportFormat == exportFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(zones)
+	}
+
+	for i, zone := range zones {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		if err := zonefile.Write(w, zone); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportFileName returns the name of the file a zone is exported to, e.g. example.com.zone.
+func exportFileName(zone zonefile.Zone) string {
+	extension := ".zone"
+	if exportFormat == exportFormatJSON {
+		extension = ".json"
+	}
+
+	return strings.TrimSuffix(zone.Origin, ".") + extension
+}
+
+func init() {
+	exportCmd.Flags().StringSliceVar(&exportZones, "zone", []string{}, "Specifies the dns names of the zones to export, separated by commas. All zones are exported if it is empty.")
+	exportCmd.Flags().StringVar(&exportFormat, "format", exportFormatBind, "Specifies the output format. Possible values are: bind, json")
+	exportCmd.Flags().StringVar(&exportOutputDir, "output-dir", "", "Specifies the directory to write one file per zone to. The zones are written to stdout if it is empty.")
+
+	rootCmd.AddCommand(exportCmd)
+}
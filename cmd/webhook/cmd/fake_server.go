@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+// fakeProjectId is the project of the fake zones if no project id is given.
+const fakeProjectId = "fake-project"
+
+var (
+	fakePort             string
+	fakeZones            []string
+	fakeLatency          time.Duration
+	fakeRateLimitRatio   float64
+	fakeServerErrorRatio float64
+	fakeRecordQuota      int
+)
+
+var fakeServerCmd = &cobra.Command{
+	Use:   "fake-server",
+	Short: "runs an in-memory fake of the STACKIT DNS API",
+	Long: "Runs an in-memory fake of the STACKIT DNS API for local development. Start the webhook with " +
+		"'--base-url=http://localhost:<port>' and any '--auth-token' to use it. All data is lost on exit.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logger := getLogger()
+
+		server := fake.NewServer()
+		server.SetRecordQuota(fakeRecordQuota)
+
+		projects := projectIDs
+		if len(projects) == 0 {
+			projects = []string{fakeProjectId}
+		}
+
+		for _, projectId := range projects {
+			for _, dnsName := range fakeZones {
+				zone := server.AddZone(projectId, dnsName)
+				logger.Info("created zone", zap.String("project", projectId), zap.String("zone", dnsName), zap.String("id", zone.Id))
+			}
+		}
+
+		if fakeLatency > 0 {
+			server.InjectFault(fake.Fault{Latency: fakeLatency})
+		}
+		if fakeRateLimitRatio > 0 {
+			fault := fake.RateLimitFault("")
+			fault.Probability = fakeRateLimitRatio
+			server.InjectFault(fault)
+		}
+		if fakeServerErrorRatio > 0 {
+			fault := fake.ServerErrorFault("")
+			fault.Probability = fakeServerErrorRatio
+			server.InjectFault(fault)
+		}
+
+		httpServer := &http.Server{
+			Addr:              fmt.Sprintf(":%s", fakePort),
+			Handler:           server,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				logger.Error("error shutting down fake server", zap.Error(err))
+			}
+		}()
+
+		logger.Info("fake STACKIT DNS API listening", zap.String("port", fakePort))
+
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	fakeServerCmd.Flags().StringVar(&fakePort, "port", "8080", "Specifies the port the fake API listens on.")
+	fakeServerCmd.Flags().StringSliceVar(&fakeZones, "zone", []string{"example.com"}, "Specifies the dns names of the zones created in every project given by '--project-id', separated by commas.")
+	fakeServerCmd.Flags().DurationVar(&fakeLatency, "latency", 0, "Specifies the latency added to every response.")
+	fakeServerCmd.Flags().Float64Var(&fakeRateLimitRatio, "rate-limit-ratio", 0, "Specifies the ratio of requests answered with 429 Too Many Requests, between 0 and 1.")
+	fakeServerCmd.Flags().Float64Var(&fakeServerErrorRatio, "server-error-ratio", 0, "Specifies the ratio of requests answered with 500 Internal Server Error, between 0 and 1.")
+	fakeServerCmd.Flags().IntVar(&fakeRecordQuota, "record-quota", 0, "Specifies the maximum number of records per zone. A value of 0 disables the quota.")
+
+	rootCmd.AddCommand(fakeServerCmd)
+}
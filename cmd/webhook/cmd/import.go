@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/zonefile"
+)
+
+var (
+	importZone  string
+	importPrune bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <zone file>",
+	Short: "imports a zone file into a zone",
+	Long: "Imports a BIND zone file (RFC 1035) into an existing zone of the STACKIT projects given by '--project-id', " +
+		"e.g. to migrate a domain from another DNS provider. The record sets of the zone are compared with the file " +
+		"and the differences are applied in the same order as the changes of external-dns. The plan is printed " +
+		"first, with '--dry-run' nothing is applied.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		zone, err := zonefile.Parse(file, importZone)
+		if err != nil {
+			return fmt.Errorf("parsing zone file %q: %w", args[0], err)
+		}
+
+		logger := getCommandLogger()
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+		defer cancel()
+
+		stackitProvider, err := newCommandProvider(ctx, logger)
+		if err != nil {
+			return err
+		}
+
+		changes, err := stackitProvider.PlanZoneImport(ctx, zone, importPrune)
+		if err != nil {
+			return err
+		}
+
+		if err := writeImportPlan(cmd.OutOrStdout(), changes); err != nil {
+			return err
+		}
+
+		if dryRun || !changes.HasChanges() {
+			return nil
+		}
+
+		if err := stackitProvider.ApplyChanges(ctx, changes); err != nil {
+			return err
+		}
+
+		logger.Info("imported zone file", zap.String("zone", zone.Origin), zap.String("file", args[0]))
+
+		return nil
+	},
+}
+
+// writeImportPlan writes one line per change, in the order in which the changes are applied: deletions, updates and
+// creations.
+func writeImportPlan(w io.Writer, changes *plan.Changes) error {
+	if !changes.HasChanges() {
+		_, err := fmt.Fprintln(w, "zone is up to date, no changes")
+
+		return err
+	}
+
+	var lines []string
+	for _, ep := range changes.Delete {
+		lines = append(lines, fmt.Sprintf("DELETE %s", formatPlanEndpoint(ep)))
+	}
+	for i, ep := range changes.UpdateNew {
+		lines = append(lines, fmt.Sprintf("UPDATE %s -> %s", formatPlanEndpoint(changes.UpdateOld[i]), formatPlanRecords(ep)))
+	}
+	for _, ep := range changes.Create {
+		lines = append(lines, fmt.Sprintf("CREATE %s", formatPlanEndpoint(ep)))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(lines, "\n"))
+
+	return err
+}
+
+// formatPlanEndpoint returns the name, type, TTL and targets of an endpoint.
+func formatPlanEndpoint(ep *endpoint.Endpoint) string {
+	return fmt.Sprintf("%s %s %s", ep.DNSName, ep.RecordType, formatPlanRecords(ep))
+}
+
+// formatPlanRecords returns the TTL and targets of an endpoint.
+func formatPlanRecords(ep *endpoint.Endpoint) string {
+	return fmt.Sprintf("%d %s", ep.RecordTTL, strings.Join(ep.Targets, " "))
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importZone, "zone", "", "Specifies the dns name of the zone to import into. It is the origin of the zone file unless the file sets another one with $ORIGIN.")
+	importCmd.Flags().BoolVar(&importPrune, "prune", false, "Specifies whether record sets of the zone which are missing in the zone file are deleted.")
+	_ = importCmd.MarkFlagRequired("zone")
+
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/internal/stackitprovider"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/config"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/reload"
+)
+
+const (
+	// reloadDebounce is the time to wait for further file events before reloading.
+	reloadDebounce = time.Second
+
+	reloadSourceAuthKey = "auth-key"
+	reloadSourceConfig  = "config"
+)
+
+// reloader rebuilds the STACKIT API client whenever the service account key or the configuration file changes.
+type reloader struct {
+	logger             *zap.Logger
+	provider           *stackitprovider.StackitDNSProvider
+	metrics            metrics.ReloadMetrics
+	rateLimiterMetrics metrics.RateLimiterMetrics
+	providerMetrics    metrics.ProviderMetrics
+	watcher            *reload.Watcher
+	flags              *pflag.FlagSet
+	// cancelClient stops the background token refresh of the current API client.
+	cancelClient context.CancelFunc
+}
+
+// startReloader watches the service account key and the configuration file in the background. Only the settings
+// of the API client take effect on reload, all other settings require a restart.
+func startReloader(
+	logger *zap.Logger,
+	provider *stackitprovider.StackitDNSProvider,
+	rateLimiterMetrics metrics.RateLimiterMetrics,
+	providerMetrics metrics.ProviderMetrics,
+	flags *pflag.FlagSet,
+	cancelClient context.CancelFunc,
+) error {
+	watcher, err := reload.NewWatcher(logger, reloadDebounce)
+	if err != nil {
+		return err
+	}
+
+	r := &reloader{
+		logger:             logger,
+		provider:           provider,
+		metrics:            metrics.NewReloadMetrics(),
+		rateLimiterMetrics: rateLimiterMetrics,
+		providerMetrics:    providerMetrics,
+		watcher:            watcher,
+		flags:              flags,
+		cancelClient:       cancelClient,
+	}
+
+	for _, path := range []string{authKeyPath, configPath} {
+		if path == "" {
+			continue
+		}
+
+		if err := watcher.Add(path); err != nil {
+			return err
+		}
+	}
+
+	go watcher.Run(context.Background(), r.reload)
+
+	return nil
+}
+
+// reload rebuilds the API client after the given files changed. The current client is kept if that fails.
+func (r *reloader) reload(paths []string) {
+	source := reloadSourceAuthKey
+	if configPath != "" && slices.Contains(paths, absPath(configPath)) {
+		source = reloadSourceConfig
+	}
+
+	logFields := []zap.Field{zap.String("source", source), zap.Strings("files", paths)}
+
+	if source == reloadSourceConfig {
+		if err := r.reloadConfigFile(); err != nil {
+			r.fail(source, err, logFields)
+
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stackitConfigOptions, err := getStackitConfigOptions(ctx, r.rateLimiterMetrics, r.providerMetrics)
+	if err == nil {
+		err = r.provider.UpdateAPIClient(stackitConfigOptions...)
+	}
+	if err != nil {
+		cancel()
+		r.fail(source, err, logFields)
+
+		return
+	}
+
+	r.cancelClient()
+	r.cancelClient = cancel
+
+	r.metrics.CollectReload(source)
+	r.logger.Info("reloaded STACKIT API client", logFields...)
+}
+
+// reloadConfigFile applies the configuration file to all parameters which are neither given on the command line
+// nor as environment variable. A new service account key path is watched from now on.
+func (r *reloader) reloadConfigFile() error {
+	configFile, err := config.Load(configPath)
+	if err != nil {
+		return err
+	}
+
+	if err := configFile.ApplyToFlags(r.flags); err != nil {
+		return err
+	}
+
+	if authKeyPath != "" {
+		return r.watcher.Add(authKeyPath)
+	}
+
+	return nil
+}
+
+func (r *reloader) fail(source string, err error, logFields []zap.Field) {
+	r.metrics.CollectReloadFailure(source)
+	r.logger.Error("error reloading STACKIT API client, keeping the current client", append(logFields, zap.Error(err))...)
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	return abs
+}
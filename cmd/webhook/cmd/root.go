@@ -1,34 +1,75 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"sigs.k8s.io/external-dns/endpoint"
 
 	"github.com/stackitcloud/external-dns-stackit-webhook/internal/stackitprovider"
 	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/api"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/audit"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/config"
 	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics"
 	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/tracing"
 )
 
 var (
+	configPath      string
 	apiPort         string
 	authBearerToken string
 	authKeyPath     string
 	tokenUrl        string
 	baseUrl         string
-	projectID       string
+	projectIDs      []string
+	projectDomains  map[string]string
 	worker          int
 	domainFilter    []string
 	dryRun          bool
 	logLevel        string
+	cacheEnabled    bool
+	cacheTTL        time.Duration
+	retryAttempts   int
+	retryBackoff    time.Duration
+	retryMaxBackoff time.Duration
+	rateLimitRPS    float64
+	rateLimitBurst  int
+	hotReload       bool
+	tracingEndpoint string
+	tracingInsecure bool
+	tracingRatio    float64
+
+	maxDeletions            int
+	maxDeletionsPercent     float64
+	zoneMaxDeletions        map[string]int
+	zoneMaxDeletionsPercent map[string]string
+	allowMassDeletion       bool
+	protectedNames          []string
+	protectedTypes          []string
+	updateConflictPolicy    string
+	optimisticConcurrency   bool
+	failureMode             string
+	quarantineThreshold     int
+	quarantineBackoff       time.Duration
+	quarantineMaxBackoff    time.Duration
+
+	auditLogStdout     bool
+	auditLogFile       string
+	auditLogMaxSize    int
+	auditLogMaxBackups int
 )
 
 var rootCmd = &cobra.Command{
@@ -46,7 +87,42 @@ var rootCmd = &cobra.Command{
 
 		endpointDomainFilter := endpoint.DomainFilter{Filters: domainFilter}
 
-		stackitConfigOptions, err := stackit.SetConfigOptions(baseUrl, authBearerToken, authKeyPath, tokenUrl)
+		// tracing is set up first, so that the API client and the provider use the configured tracer provider
+		shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+			Endpoint:    tracingEndpoint,
+			Insecure:    tracingInsecure,
+			SampleRatio: tracingRatio,
+		})
+		if err != nil {
+			panic(err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if err := shutdownTracing(ctx); err != nil {
+				logger.Error("error shutting down tracing", zap.Error(err))
+			}
+		}()
+
+		rateLimiterMetrics := metrics.NewRateLimiterMetrics()
+		providerMetrics := metrics.NewProviderMetrics()
+
+		// the context stops the background token refresh once the client is replaced on reload
+		clientCtx, cancelClient := context.WithCancel(context.Background())
+		defer cancelClient()
+
+		stackitConfigOptions, err := getStackitConfigOptions(clientCtx, rateLimiterMetrics, providerMetrics)
+		if err != nil {
+			panic(err)
+		}
+
+		deletionGuard, err := getDeletionGuard()
+		if err != nil {
+			panic(err)
+		}
+
+		auditLog, err := getAuditLog()
 		if err != nil {
 			panic(err)
 		}
@@ -55,10 +131,28 @@ var rootCmd = &cobra.Command{
 			logger.With(zap.String("component", "stackitprovider")),
 			// ExternalDNS provider config
 			&stackitprovider.Config{
-				ProjectId:    projectID,
-				DomainFilter: endpointDomainFilter,
-				DryRun:       dryRun,
-				Workers:      worker,
+				ProjectIds:     projectIDs,
+				DomainProjects: projectDomains,
+				DomainFilter:   endpointDomainFilter,
+				DryRun:         dryRun,
+				Workers:        worker,
+				DeletionGuard:  deletionGuard,
+				ProtectedRecords: stackitprovider.ProtectedRecords{
+					Names: protectedNames,
+					Types: protectedTypes,
+				},
+				ConflictPolicy:        stackitprovider.ConflictPolicy(updateConflictPolicy),
+				OptimisticConcurrency: optimisticConcurrency,
+				FailureMode:           stackitprovider.FailureMode(failureMode),
+				Quarantine: stackitprovider.QuarantinePolicy{
+					Threshold:  quarantineThreshold,
+					Backoff:    quarantineBackoff,
+					MaxBackoff: quarantineMaxBackoff,
+				},
+				CacheEnabled: cacheEnabled,
+				CacheTTL:     cacheTTL,
+				Metrics:      providerMetrics,
+				AuditLog:     auditLog,
 			},
 			// STACKIT client SDK config
 			stackitConfigOptions...,
@@ -67,6 +161,13 @@ var rootCmd = &cobra.Command{
 			panic(err)
 		}
 
+		if hotReload {
+			err = startReloader(logger.With(zap.String("component", "reload")), stackitProvider, rateLimiterMetrics, providerMetrics, cmd.PersistentFlags(), cancelClient)
+			if err != nil {
+				panic(err)
+			}
+		}
+
 		app := api.New(logger.With(zap.String("component", "api")), metrics.NewHttpApiMetrics(), stackitProvider)
 		err = app.Listen(apiPort)
 		if err != nil {
@@ -75,11 +176,139 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// getStackitConfigOptions returns the options of the STACKIT API client built from the current parameters.
+func getStackitConfigOptions(
+	ctx context.Context,
+	rateLimiterMetrics metrics.RateLimiterMetrics,
+	providerMetrics metrics.ProviderMetrics,
+) ([]stackitconfig.ConfigurationOption, error) {
+	return stackit.SetConfigOptions(
+		ctx,
+		baseUrl, authBearerToken, authKeyPath, tokenUrl,
+		stackit.RetryOptions{
+			MaxAttempts:    retryAttempts,
+			InitialBackoff: retryBackoff,
+			MaxBackoff:     retryMaxBackoff,
+		},
+		stackit.RateLimitOptions{
+			RequestsPerSecond: rateLimitRPS,
+			Burst:             rateLimitBurst,
+			Metrics:           rateLimiterMetrics,
+		},
+		providerMetrics,
+	)
+}
+
+// newCommandProvider creates a provider for a command run from the command line, without cache and metrics. The
+// context stops the background token refresh of the API client.
+func newCommandProvider(ctx context.Context, logger *zap.Logger) (*stackitprovider.StackitDNSProvider, error) {
+	stackitConfigOptions, err := getStackitConfigOptions(ctx, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deletionGuard, err := getDeletionGuard()
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog, err := getAuditLog()
+	if err != nil {
+		return nil, err
+	}
+
+	return stackitprovider.NewStackitDNSProvider(
+		logger.With(zap.String("component", "stackitprovider")),
+		&stackitprovider.Config{
+			ProjectIds:     projectIDs,
+			DomainProjects: projectDomains,
+			DomainFilter:   endpoint.DomainFilter{Filters: domainFilter},
+			DryRun:         dryRun,
+			Workers:        worker,
+			DeletionGuard:  deletionGuard,
+			ProtectedRecords: stackitprovider.ProtectedRecords{
+				Names: protectedNames,
+				Types: protectedTypes,
+			},
+			ConflictPolicy:        stackitprovider.ConflictPolicy(updateConflictPolicy),
+			OptimisticConcurrency: optimisticConcurrency,
+			FailureMode:           stackitprovider.FailureMode(failureMode),
+			AuditLog:              auditLog,
+		},
+		stackitConfigOptions...,
+	)
+}
+
+// getDeletionGuard returns the deletion guard built from the current parameters. The limits of a zone which are
+// not set for the zone itself are taken from the default limits.
+func getDeletionGuard() (stackitprovider.DeletionGuard, error) {
+	guard := stackitprovider.DeletionGuard{
+		Default:  stackitprovider.DeletionLimit{Count: maxDeletions, Percent: maxDeletionsPercent},
+		Zones:    map[string]stackitprovider.DeletionLimit{},
+		Override: allowMassDeletion,
+	}
+
+	zoneLimit := func(zone string) stackitprovider.DeletionLimit {
+		if limit, ok := guard.Zones[zone]; ok {
+			return limit
+		}
+
+		return guard.Default
+	}
+
+	for zone, count := range zoneMaxDeletions {
+		limit := zoneLimit(zone)
+		limit.Count = count
+		guard.Zones[zone] = limit
+	}
+
+	for zone, value := range zoneMaxDeletionsPercent {
+		percent, err := strconv.ParseFloat(value, 64)
+		if err != nil || percent < 0 || percent > 100 {
+			return stackitprovider.DeletionGuard{}, fmt.Errorf("invalid percentage %q of zone %q, must be between 0 and 100", value, zone)
+		}
+
+		limit := zoneLimit(zone)
+		limit.Percent = percent
+		guard.Zones[zone] = limit
+	}
+
+	return guard, nil
+}
+
+// getAuditLog returns the audit log built from the current parameters. Without sinks, it writes nothing. The file is
+// kept open until the process exits.
+func getAuditLog() (*audit.Log, error) {
+	var sinks []io.Writer
+	if auditLogStdout {
+		sinks = append(sinks, os.Stdout)
+	}
+
+	if auditLogFile != "" {
+		file, err := audit.NewRotatingFile(auditLogFile, int64(auditLogMaxSize)*1024*1024, auditLogMaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("opening audit log file: %w", err)
+		}
+		sinks = append(sinks, file)
+	}
+
+	return audit.New(sinks...), nil
+}
+
 func getLogger() *zap.Logger {
+	return buildLogger("stdout")
+}
+
+// getCommandLogger returns a logger writing to stderr, so that the logs do not mix with the output of a command.
+func getCommandLogger() *zap.Logger {
+	return buildLogger("stderr")
+}
+
+func buildLogger(outputPath string) *zap.Logger {
 	cfg := zap.Config{
 		Level:            zap.NewAtomicLevelAt(getZapLogLevel()),
 		Encoding:         "json",
-		OutputPaths:      []string{"stdout"},
+		OutputPaths:      []string{outputPath},
 		ErrorOutputPaths: []string{"stderr"},
 	}
 
@@ -113,16 +342,48 @@ func Execute() error {
 func init() {
 	cobra.OnInitialize(initConfig)
 
+	defaults := stackitprovider.DefaultConfig()
+
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Specifies the path of a YAML or JSON configuration file. Command line parameters and environment variables take precedence over the file.")
 	rootCmd.PersistentFlags().StringVar(&apiPort, "api-port", "8888", "Specifies the port to listen on.")
 	rootCmd.PersistentFlags().StringVar(&authBearerToken, "auth-token", "", "Defines the authentication token for the STACKIT API. Mutually exclusive with 'auth-key-path'.")
 	rootCmd.PersistentFlags().StringVar(&authKeyPath, "auth-key-path", "", "Defines the file path of the service account key for the STACKIT API. Mutually exclusive with 'auth-token'.")
 	rootCmd.PersistentFlags().StringVar(&tokenUrl, "token-url", "", "Defines the authentication token endpoint for the STACKIT API.")
 	rootCmd.PersistentFlags().StringVar(&baseUrl, "base-url", "https://dns.api.stackit.cloud", " Identifies the Base URL for utilizing the API.")
-	rootCmd.PersistentFlags().StringVar(&projectID, "project-id", "", "Specifies the project id of the STACKIT project.")
-	rootCmd.PersistentFlags().IntVar(&worker, "worker", 10, "Specifies the number of workers to employ for querying the API. Given that we need to iterate over all zones and records, it can be parallelized. However, it is important to avoid setting this number excessively high to prevent receiving 429 rate limiting from the API.")
+	rootCmd.PersistentFlags().StringSliceVar(&projectIDs, "project-id", []string{}, "Specifies the project ids of the STACKIT projects, separated by commas. The zones of all projects are managed.")
+	rootCmd.PersistentFlags().StringToStringVar(&projectDomains, "project-domain", map[string]string{}, "Maps domains to the STACKIT projects owning them, e.g. 'staging.example.com=<project id>'. The zones of a mapped project are restricted to its domains.")
+	rootCmd.PersistentFlags().IntVar(&worker, "worker", defaults.Workers, "Specifies the number of workers to employ for querying the API. Given that we need to iterate over all zones and records, it can be parallelized. However, it is important to avoid setting this number excessively high to prevent receiving 429 rate limiting from the API.")
 	rootCmd.PersistentFlags().StringArrayVar(&domainFilter, "domain-filter", []string{}, "Establishes a filter for DNS zone names")
 	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Specifies whether to perform a dry run.")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Specifies the log level. Possible values are: debug, info, warn, error")
+	rootCmd.PersistentFlags().BoolVar(&cacheEnabled, "cache-enabled", defaults.CacheEnabled, "Specifies whether zones and record sets are cached in memory to reduce the number of API calls. Changes made outside the webhook are only seen after the cache TTL.")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", defaults.CacheTTL, "Specifies how long cached zones and record sets are used before they are fetched again from the API.")
+	rootCmd.PersistentFlags().IntVar(&retryAttempts, "retry-max-attempts", 3, "Specifies the maximum number of attempts for a request to the API that failed with 429 or 5xx. A value of 1 disables retries.")
+	rootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-initial-backoff", time.Second, "Specifies the wait time before the first retry. It doubles with every further retry.")
+	rootCmd.PersistentFlags().DurationVar(&retryMaxBackoff, "retry-max-backoff", 30*time.Second, "Specifies the maximum wait time between two attempts. Requests are not retried if the API asks for a longer wait time via Retry-After.")
+	rootCmd.PersistentFlags().Float64Var(&rateLimitRPS, "rate-limit-rps", 0, "Specifies the maximum number of requests per second to the API, shared by all workers. A value of 0 disables the rate limit.")
+	rootCmd.PersistentFlags().BoolVar(&hotReload, "hot-reload", true, "Specifies whether the API client is rebuilt without restart when the service account key or the configuration file changes.")
+	rootCmd.PersistentFlags().IntVar(&rateLimitBurst, "rate-limit-burst", 10, "Specifies the number of requests to the API which may be sent at once before the rate limit applies.")
+	rootCmd.PersistentFlags().StringVar(&tracingEndpoint, "tracing-endpoint", "", "Specifies the host and port of an OTLP HTTP receiver, e.g. 'localhost:4318', to export OpenTelemetry traces to. Tracing is disabled if it is empty.")
+	rootCmd.PersistentFlags().BoolVar(&tracingInsecure, "tracing-insecure", false, "Specifies whether traces are exported without TLS.")
+	rootCmd.PersistentFlags().Float64Var(&tracingRatio, "tracing-sample-ratio", 1, "Specifies the ratio of traces which are sampled, between 0 and 1.")
+	rootCmd.PersistentFlags().IntVar(&maxDeletions, "max-deletions", 0, "Specifies the maximum number of record sets deleted in a zone by a single change set, not counting the ownership TXT records of the registry. Change sets exceeding it are refused. A value of 0 disables the limit.")
+	rootCmd.PersistentFlags().Float64Var(&maxDeletionsPercent, "max-deletions-percent", 0, "Specifies the maximum percentage of the record sets of a zone deleted by a single change set, not counting the ownership TXT records of the registry. Change sets exceeding it are refused. A value of 0 disables the limit.")
+	rootCmd.PersistentFlags().StringToIntVar(&zoneMaxDeletions, "zone-max-deletions", map[string]int{}, "Overrides 'max-deletions' for single zones, e.g. 'example.com=50'.")
+	rootCmd.PersistentFlags().StringToStringVar(&zoneMaxDeletionsPercent, "zone-max-deletions-percent", map[string]string{}, "Overrides 'max-deletions-percent' for single zones, e.g. 'example.com=10'.")
+	rootCmd.PersistentFlags().StringArrayVar(&protectedNames, "protected-name", []string{}, "Specifies the names of record sets which are never created, updated or deleted. A name is matched exactly, as glob pattern if it contains one of *?[ or as regular expression if it is enclosed in slashes.")
+	rootCmd.PersistentFlags().StringSliceVar(&protectedTypes, "protected-type", []string{}, "Specifies record types whose record sets are never created, updated or deleted, separated by commas.")
+	rootCmd.PersistentFlags().StringVar(&updateConflictPolicy, "update-conflict-policy", string(defaults.ConflictPolicy), "Specifies what happens to an update of a record set which was changed since external-dns read it. Possible values are: ignore, flag, refuse")
+	rootCmd.PersistentFlags().BoolVar(&optimisticConcurrency, "optimistic-concurrency", defaults.OptimisticConcurrency, "Specifies whether every record set is re-read just before it is updated or deleted. A change of a record set which differs from the state the change is based on is skipped.")
+	rootCmd.PersistentFlags().StringVar(&failureMode, "failure-mode", string(defaults.FailureMode), "Specifies how a change set continues once a change failed. Possible values are: fail-fast, partial")
+	rootCmd.PersistentFlags().IntVar(&quarantineThreshold, "quarantine-threshold", defaults.Quarantine.Threshold, "Specifies the number of consecutive failures of the changes of a record set after which the record set is quarantined. A value of 0 disables the quarantine.")
+	rootCmd.PersistentFlags().DurationVar(&quarantineBackoff, "quarantine-backoff", defaults.Quarantine.Backoff, "Specifies the time after which the change of a quarantined record set is retried. It doubles with every further failure.")
+	rootCmd.PersistentFlags().DurationVar(&quarantineMaxBackoff, "quarantine-max-backoff", defaults.Quarantine.MaxBackoff, "Specifies the maximum time after which the change of a quarantined record set is retried. A value of 0 does not limit the backoff.")
+	rootCmd.PersistentFlags().BoolVar(&auditLogStdout, "audit-log-stdout", false, "Specifies whether an audit entry of every mutation of a record set is written to stdout as JSON line.")
+	rootCmd.PersistentFlags().StringVar(&auditLogFile, "audit-log-file", "", "Specifies the path of a JSON lines file the audit entries of every mutation of a record set are appended to. The file is disabled if it is empty.")
+	rootCmd.PersistentFlags().IntVar(&auditLogMaxSize, "audit-log-max-size", 100, "Specifies the size in megabytes after which the audit log file is rotated. A value of 0 disables the rotation.")
+	rootCmd.PersistentFlags().IntVar(&auditLogMaxBackups, "audit-log-max-backups", 5, "Specifies the number of rotated audit log files which are kept.")
+	rootCmd.PersistentFlags().BoolVar(&allowMassDeletion, "allow-mass-deletion", false, "Specifies whether change sets exceeding the deletion limits are applied anyway, e.g. for an intentional cleanup.")
 }
 
 func initConfig() {
@@ -142,4 +403,17 @@ func initConfig() {
 			}
 		}
 	})
+
+	// the configuration file is applied last, so it only sets the parameters which are neither given on the
+	// command line nor as environment variable
+	if configPath != "" {
+		configFile, err := config.Load(configPath)
+		if err != nil {
+			log.Fatalf("invalid configuration file: %v", err)
+		}
+
+		if err := configFile.ApplyToFlags(rootCmd.PersistentFlags()); err != nil {
+			log.Fatalf("invalid configuration file %q: %v", configPath, err)
+		}
+	}
 }
@@ -0,0 +1,495 @@
+package conformance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+	"sigs.k8s.io/external-dns/provider/webhook"
+	webhookapi "sigs.k8s.io/external-dns/provider/webhook/api"
+	"sigs.k8s.io/external-dns/registry"
+	"sigs.k8s.io/external-dns/registry/txt"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/internal/stackitprovider"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/api"
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+const (
+	projectId = "conformance-project"
+	ownerId   = "conformance"
+	// commentProperty is the provider-specific property of the record set comment.
+	commentProperty = "webhook/stackit-comment"
+)
+
+// configurations are the webhook configurations the idempotency of the reconciliation is checked with, changing the
+// defaults of the command line parameters.
+var configurations = []struct {
+	name   string
+	modify func(config *stackitprovider.Config)
+}{
+	{"Defaults", nil},
+	{"Cache", func(config *stackitprovider.Config) { config.CacheEnabled = true }},
+}
+
+// stack is the webhook running against a fake STACKIT DNS API, seen through the external-dns webhook client.
+type stack struct {
+	fake       *fake.Server
+	zone       stackitdnsclient.Zone
+	webhookURL string
+	client     provider.Provider
+}
+
+func TestNegotiation(t *testing.T) {
+	t.Parallel()
+
+	s := newStack(t)
+
+	req, err := http.NewRequest(http.MethodGet, s.webhookURL, nil)
+	assert.NoError(t, err)
+	req.Header.Set("Accept", webhookapi.MediaTypeFormatAndVersion)
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, webhookapi.MediaTypeFormatAndVersion, resp.Header.Get(webhookapi.ContentTypeHeader))
+	assert.Equal(t, webhookapi.ContentTypeHeader, resp.Header.Get("Vary"))
+
+	// the client negotiated the domain filter of the provider
+	domainFilter := s.client.GetDomainFilter()
+	assert.True(t, domainFilter.Match("www.example.com"))
+	assert.False(t, domainFilter.Match("www.example.org"))
+}
+
+func TestRecords(t *testing.T) {
+	t.Parallel()
+
+	s := newStack(t)
+	_, err := s.fake.AddRecordSet(s.zone.Id, "www.example.com.", endpoint.RecordTypeA, 300, "1.2.3.4", "5.6.7.8")
+	assert.NoError(t, err)
+
+	endpoints, err := s.client.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "www.example.com", RecordType: endpoint.RecordTypeA, RecordTTL: 300, Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+	}, endpoints)
+}
+
+func TestAdjustEndpoints(t *testing.T) {
+	t.Parallel()
+
+	s := newStack(t)
+
+	endpoints, err := s.client.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("WWW.example.com", endpoint.RecordTypeA, "1.2.3.4", "1.2.3.4"),
+		endpoint.NewEndpointWithTTL("api.example.com", endpoint.RecordTypeCNAME, 10, "Backend.Example.com"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "www.example.com.", RecordType: endpoint.RecordTypeA, RecordTTL: 300, Targets: endpoint.Targets{"1.2.3.4"}},
+		{DNSName: "api.example.com.", RecordType: endpoint.RecordTypeCNAME, RecordTTL: 60, Targets: endpoint.Targets{"backend.example.com"}},
+	}, endpoints)
+}
+
+func TestApplyChangesStatus(t *testing.T) {
+	t.Parallel()
+
+	s := newStack(t)
+
+	err := s.client.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, s.fake.RecordSets(s.zone.Id), 1)
+
+	resp, err := http.Post(s.webhookURL+"/records", webhookapi.MediaTypeFormatAndVersion, strings.NewReader("{"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// errors of the STACKIT API are reported as server errors, which external-dns retries
+	s.fake.InjectFault(fake.ServerErrorFault(fake.OperationCreateRecordSet))
+
+	err = s.client.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+	})
+	assert.ErrorIs(t, err, provider.SoftError)
+}
+
+func TestReconcile(t *testing.T) {
+	t.Parallel()
+
+	s := newStack(t)
+	source := &staticSource{}
+	r := s.newReconciler(t, source)
+	ctx := context.Background()
+
+	// create
+	source.endpoints = []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.2.3.4")}
+	assert.NoError(t, r.RunOnce(ctx))
+
+	rrSet, ok := s.recordSet("www.example.com.", endpoint.RecordTypeA)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"1.2.3.4"}, contents(rrSet))
+
+	ownership, ok := s.recordSet("a-www.example.com.", endpoint.RecordTypeTXT)
+	if assert.True(t, ok, "expected an ownership TXT record") {
+		assert.Contains(t, contents(ownership)[0], "external-dns/owner="+ownerId)
+	}
+
+	// a second run with the same desired state changes nothing
+	assert.NoError(t, r.RunOnce(ctx))
+	assert.Equal(t, 2, s.fake.Requests(fake.OperationCreateRecordSet))
+	assert.Zero(t, s.fake.Requests(fake.OperationPartialUpdateRecordSet))
+	assert.Zero(t, s.fake.Requests(fake.OperationDeleteRecordSet))
+
+	// update
+	source.endpoints = []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "5.6.7.8")}
+	assert.NoError(t, r.RunOnce(ctx))
+
+	rrSet, ok = s.recordSet("www.example.com.", endpoint.RecordTypeA)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"5.6.7.8"}, contents(rrSet))
+	assert.Equal(t, 2, s.fake.Requests(fake.OperationCreateRecordSet))
+
+	updates := s.fake.Requests(fake.OperationPartialUpdateRecordSet)
+	assert.NotZero(t, updates)
+
+	assert.NoError(t, r.RunOnce(ctx))
+	assert.Equal(t, updates, s.fake.Requests(fake.OperationPartialUpdateRecordSet))
+
+	// delete
+	source.endpoints = nil
+	assert.NoError(t, r.RunOnce(ctx))
+	assert.Empty(t, s.fake.RecordSets(s.zone.Id))
+
+	assert.NoError(t, r.RunOnce(ctx))
+	assert.Equal(t, 2, s.fake.Requests(fake.OperationDeleteRecordSet))
+}
+
+func TestReconcileComments(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range configurations {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := newStackWithConfig(t, tt.modify)
+			source := &staticSource{endpoints: []*endpoint.Endpoint{
+				endpoint.NewEndpoint("commented.example.com", endpoint.RecordTypeA, "1.1.1.1").
+					WithProviderSpecific(commentProperty, "owner: team-a"),
+				endpoint.NewEndpoint("empty.example.com", endpoint.RecordTypeA, "2.2.2.2").
+					WithProviderSpecific(commentProperty, ""),
+				endpoint.NewEndpoint("portal.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+			}}
+			r := s.newReconciler(t, source)
+			ctx := context.Background()
+
+			assert.NoError(t, r.RunOnce(ctx))
+
+			commented, _ := s.recordSet("commented.example.com.", endpoint.RecordTypeA)
+			assert.Equal(t, new("owner: team-a"), commented.Comment)
+
+			// a comment set in the portal is kept by the record without the annotation
+			err := s.fake.UpdateRecordSet(s.zone.Id, "portal.example.com.", endpoint.RecordTypeA, func(rrSet *stackitdnsclient.RecordSet) {
+				rrSet.Comment = new("set in the portal")
+			})
+			assert.NoError(t, err)
+
+			// further runs with the same desired state change nothing
+			for range 3 {
+				assert.NoError(t, r.RunOnce(ctx))
+			}
+			assert.Zero(t, s.fake.Requests(fake.OperationPartialUpdateRecordSet))
+
+			portal, _ := s.recordSet("portal.example.com.", endpoint.RecordTypeA)
+			assert.Equal(t, new("set in the portal"), portal.Comment)
+
+			// removing the comment with an empty annotation updates the record set once
+			source.endpoints[0] = endpoint.NewEndpoint("commented.example.com", endpoint.RecordTypeA, "1.1.1.1").
+				WithProviderSpecific(commentProperty, "")
+			assert.NoError(t, r.RunOnce(ctx))
+
+			updates := s.fake.Requests(fake.OperationPartialUpdateRecordSet)
+			assert.NotZero(t, updates)
+
+			for range 2 {
+				assert.NoError(t, r.RunOnce(ctx))
+			}
+			assert.Equal(t, updates, s.fake.Requests(fake.OperationPartialUpdateRecordSet))
+
+			commented, _ = s.recordSet("commented.example.com.", endpoint.RecordTypeA)
+			assert.Nil(t, commented.Comment)
+		})
+	}
+}
+
+func TestReconcileOutOfBandChanges(t *testing.T) {
+	t.Parallel()
+
+	s := newStack(t)
+	source := &staticSource{endpoints: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "1.1.1.1"),
+		endpoint.NewEndpoint("api.example.com", endpoint.RecordTypeA, "2.2.2.2"),
+	}}
+	r := s.newReconciler(t, source)
+	ctx := context.Background()
+
+	assert.NoError(t, r.RunOnce(ctx))
+
+	// the records are changed and deleted in the portal
+	err := s.fake.UpdateRecordSet(s.zone.Id, "www.example.com.", endpoint.RecordTypeA, func(rrSet *stackitdnsclient.RecordSet) {
+		rrSet.Ttl = 60
+		rrSet.Records = []stackitdnsclient.Record{{Content: "9.9.9.9"}}
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, s.fake.RemoveRecordSet(s.zone.Id, "api.example.com.", endpoint.RecordTypeA))
+
+	// the next run restores the desired state
+	assert.NoError(t, r.RunOnce(ctx))
+
+	www, _ := s.recordSet("www.example.com.", endpoint.RecordTypeA)
+	assert.Equal(t, []string{"1.1.1.1"}, contents(www))
+	assert.Equal(t, int32(300), www.Ttl)
+	apiRRSet, ok := s.recordSet("api.example.com.", endpoint.RecordTypeA)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"2.2.2.2"}, contents(apiRRSet))
+
+	// further runs with the same desired state change nothing
+	creates := s.fake.Requests(fake.OperationCreateRecordSet)
+	updates := s.fake.Requests(fake.OperationPartialUpdateRecordSet)
+	for range 3 {
+		assert.NoError(t, r.RunOnce(ctx))
+	}
+	assert.Equal(t, creates, s.fake.Requests(fake.OperationCreateRecordSet))
+	assert.Equal(t, updates, s.fake.Requests(fake.OperationPartialUpdateRecordSet))
+	assert.Zero(t, s.fake.Requests(fake.OperationDeleteRecordSet))
+}
+
+func TestReconcileOwnership(t *testing.T) {
+	t.Parallel()
+
+	s := newStack(t)
+
+	// a record without ownership record and a record owned by another instance
+	_, err := s.fake.AddRecordSet(s.zone.Id, "manual.example.com.", endpoint.RecordTypeA, 300, "1.1.1.1")
+	assert.NoError(t, err)
+	_, err = s.fake.AddRecordSet(s.zone.Id, "other.example.com.", endpoint.RecordTypeA, 300, "2.2.2.2")
+	assert.NoError(t, err)
+	_, err = s.fake.AddRecordSet(s.zone.Id, "a-other.example.com.", endpoint.RecordTypeTXT, 300,
+		`"heritage=external-dns,external-dns/owner=other-owner"`)
+	assert.NoError(t, err)
+
+	source := &staticSource{endpoints: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("manual.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+		endpoint.NewEndpoint("other.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+		endpoint.NewEndpoint("www.example.com", endpoint.RecordTypeA, "3.3.3.3"),
+	}}
+	r := s.newReconciler(t, source)
+
+	assert.NoError(t, r.RunOnce(context.Background()))
+
+	manual, _ := s.recordSet("manual.example.com.", endpoint.RecordTypeA)
+	assert.Equal(t, []string{"1.1.1.1"}, contents(manual))
+
+	other, _ := s.recordSet("other.example.com.", endpoint.RecordTypeA)
+	assert.Equal(t, []string{"2.2.2.2"}, contents(other))
+
+	www, ok := s.recordSet("www.example.com.", endpoint.RecordTypeA)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"3.3.3.3"}, contents(www))
+
+	// records not owned by this instance are never deleted
+	source.endpoints = nil
+	assert.NoError(t, r.RunOnce(context.Background()))
+
+	_, ok = s.recordSet("manual.example.com.", endpoint.RecordTypeA)
+	assert.True(t, ok)
+	_, ok = s.recordSet("other.example.com.", endpoint.RecordTypeA)
+	assert.True(t, ok)
+	_, ok = s.recordSet("www.example.com.", endpoint.RecordTypeA)
+	assert.False(t, ok)
+}
+
+// newStack starts the fake STACKIT DNS API and the webhook in front of it and connects the external-dns webhook
+// client to the webhook. The webhook runs with the defaults of the command line parameters.
+func newStack(t *testing.T) *stack {
+	t.Helper()
+
+	return newStackWithConfig(t, nil)
+}
+
+// newStackWithConfig starts a stack whose webhook configuration is changed by modify, starting from the defaults of
+// the command line parameters.
+func newStackWithConfig(t *testing.T, modify func(config *stackitprovider.Config)) *stack {
+	t.Helper()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone(projectId, "example.com")
+
+	stackitServer := httptest.NewServer(fakeServer)
+	t.Cleanup(stackitServer.Close)
+
+	config := stackitprovider.DefaultConfig()
+	config.ProjectIds = []string{projectId}
+	config.DomainFilter = *endpoint.NewDomainFilter([]string{"example.com"})
+	if modify != nil {
+		modify(&config)
+	}
+
+	stackitDnsProvider, err := stackitprovider.NewStackitDNSProvider(
+		zap.NewNop(),
+		&config,
+		stackitconfig.WithEndpoint(stackitServer.URL),
+		stackitconfig.WithToken("token"),
+	)
+	assert.NoError(t, err)
+
+	app := api.New(zap.NewNop(), newMockMetrics(t), stackitDnsProvider)
+
+	webhookServer := httptest.NewServer(app.Handler())
+	t.Cleanup(webhookServer.Close)
+
+	client, err := webhook.New(context.Background(), &externaldns.Config{
+		WebhookProviderURL:          webhookServer.URL,
+		WebhookProviderReadTimeout:  5 * time.Second,
+		WebhookProviderWriteTimeout: 10 * time.Second,
+	}, nil)
+	assert.NoError(t, err)
+
+	return &stack{
+		fake:       fakeServer,
+		zone:       zone,
+		webhookURL: webhookServer.URL,
+		client:     client,
+	}
+}
+
+// newReconciler returns a reconciler syncing the endpoints of the source through a TXT registry.
+func (s *stack) newReconciler(t *testing.T, source *staticSource) *reconciler {
+	t.Helper()
+
+	cfg := &externaldns.Config{
+		TXTOwnerID:            ownerId,
+		ManagedDNSRecordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeAAAA, endpoint.RecordTypeCNAME},
+	}
+
+	txtRegistry, err := txt.New(cfg, s.client)
+	assert.NoError(t, err)
+
+	return &reconciler{
+		source:             source,
+		registry:           txtRegistry,
+		domainFilter:       endpoint.NewDomainFilter([]string{"example.com"}),
+		managedRecordTypes: cfg.ManagedDNSRecordTypes,
+	}
+}
+
+// reconciler runs the reconciliation of the external-dns controller. The controller package itself is not used,
+// since it depends on all sources and providers of external-dns.
+type reconciler struct {
+	source             *staticSource
+	registry           registry.Registry
+	domainFilter       *endpoint.DomainFilter
+	managedRecordTypes []string
+}
+
+// RunOnce calculates the plan from the registry records and the desired endpoints and applies its changes.
+func (r *reconciler) RunOnce(ctx context.Context) error {
+	records, err := r.registry.Records(ctx)
+	if err != nil {
+		return err
+	}
+
+	desired, err := r.source.Endpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	desired, err = r.registry.AdjustEndpoints(desired)
+	if err != nil {
+		return err
+	}
+
+	calculated := (&plan.Plan{
+		Policies:       []plan.Policy{&plan.SyncPolicy{}},
+		Current:        records,
+		Desired:        desired,
+		DomainFilter:   endpoint.MatchAllDomainFilters{r.domainFilter, r.registry.GetDomainFilter()},
+		ManagedRecords: r.managedRecordTypes,
+		OwnerID:        r.registry.OwnerID(),
+	}).Calculate()
+
+	if !calculated.Changes.HasChanges() {
+		return nil
+	}
+
+	return r.registry.ApplyChanges(ctx, calculated.Changes)
+}
+
+// recordSet returns the record set with the given name and type from the fake STACKIT DNS API.
+func (s *stack) recordSet(name, recordType string) (stackitdnsclient.RecordSet, bool) {
+	for _, rrSet := range s.fake.RecordSets(s.zone.Id) {
+		if rrSet.Name == name && string(rrSet.Type) == recordType {
+			return rrSet, true
+		}
+	}
+
+	return stackitdnsclient.RecordSet{}, false
+}
+
+func contents(rrSet stackitdnsclient.RecordSet) []string {
+	result := make([]string, 0, len(rrSet.Records))
+	for _, record := range rrSet.Records {
+		result = append(result, record.Content)
+	}
+
+	return result
+}
+
+// staticSource is a source returning a fixed list of endpoints.
+type staticSource struct {
+	endpoints []*endpoint.Endpoint
+}
+
+func (s *staticSource) Endpoints(context.Context) ([]*endpoint.Endpoint, error) {
+	result := make([]*endpoint.Endpoint, 0, len(s.endpoints))
+	for _, ep := range s.endpoints {
+		result = append(result, ep.DeepCopy())
+	}
+
+	return result, nil
+}
+
+func (s *staticSource) AddEventHandler(context.Context, func()) {}
+
+func newMockMetrics(t *testing.T) *mockmetrics.MockHttpApiMetrics {
+	t.Helper()
+
+	metricsCollector := mockmetrics.NewMockHttpApiMetrics(gomock.NewController(t))
+
+	metricsCollector.EXPECT().CollectRequest(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	metricsCollector.EXPECT().CollectTotalRequests().AnyTimes()
+	metricsCollector.EXPECT().CollectRequestResponseSize(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	metricsCollector.EXPECT().CollectRequestDuration(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	metricsCollector.EXPECT().Collect400TotalRequests().AnyTimes()
+	metricsCollector.EXPECT().Collect500TotalRequests().AnyTimes()
+
+	return metricsCollector
+}
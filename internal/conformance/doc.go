@@ -0,0 +1,5 @@
+// Package conformance verifies the webhook against the external-dns webhook protocol. Its tests drive the real
+// api and provider through the webhook client, TXT registry and controller of external-dns against a fake
+// STACKIT DNS API, so that they break whenever the webhook and external-dns stop agreeing on the protocol. The
+// webhook runs with the defaults of its command line parameters, like an installation without any parameters.
+package conformance
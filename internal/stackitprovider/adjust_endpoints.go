@@ -0,0 +1,74 @@
+package stackitprovider
+
+import (
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// defaultTTL is used for endpoints without a configured TTL.
+	defaultTTL = 300
+	// minTTL is the lowest TTL accepted by the STACKIT DNS API.
+	minTTL = 60
+	// maxTTL is the highest TTL accepted by the STACKIT DNS API.
+	maxTTL = 99999999
+)
+
+// AdjustEndpoints normalizes the desired endpoints to the form in which STACKIT stores them, so that the
+// planner compares them with the result of Records without producing updates that change nothing. This includes
+// the comment property, which is aligned with the comments returned by the last call of Records.
+func (d *StackitDNSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		normalizeEndpoint(ep)
+		d.comments.align(ep)
+	}
+
+	return endpoints, nil
+}
+
+// normalizeEndpoint brings an endpoint into the canonical STACKIT form: a lowercase FQDN, a TTL within
+// the accepted range and unique targets, with TXT targets formatted the same way as they are read back.
+func normalizeEndpoint(ep *endpoint.Endpoint) {
+	ep.DNSName = strings.ToLower(ep.DNSName)
+	modifyChange(ep)
+	ep.RecordTTL = clampTTL(ep.RecordTTL)
+	ep.Targets = normalizeTargets(ep.RecordType, ep.Targets)
+}
+
+// clampTTL limits a TTL to the range accepted by the STACKIT DNS API.
+func clampTTL(ttl endpoint.TTL) endpoint.TTL {
+	switch {
+	case ttl < minTTL:
+		return minTTL
+	case ttl > maxTTL:
+		return maxTTL
+	default:
+		return ttl
+	}
+}
+
+// normalizeTargets formats the targets of an endpoint according to its record type and drops duplicates
+// while preserving the order of the remaining targets.
+func normalizeTargets(recordType string, targets endpoint.Targets) endpoint.Targets {
+	result := make(endpoint.Targets, 0, len(targets))
+	seen := make(map[string]struct{}, len(targets))
+
+	for _, target := range targets {
+		switch recordType {
+		case txtRecord:
+			// STACKIT returns chunked TXT content merged into a single quoted string
+			target = unformatTXTContent(formatTXTContent(target))
+		case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypePTR:
+			target = strings.ToLower(target)
+		}
+
+		if _, ok := seen[target]; ok {
+			continue
+		}
+		seen[target] = struct{}{}
+		result = append(result, target)
+	}
+
+	return result
+}
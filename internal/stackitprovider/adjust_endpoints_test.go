@@ -0,0 +1,132 @@
+package stackitprovider
+
+import (
+	"strings"
+	"testing"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestAdjustEndpoints(t *testing.T) {
+	t.Parallel()
+
+	longTXT := strings.Repeat("a", 256)
+
+	tests := []struct {
+		name     string
+		endpoint *endpoint.Endpoint
+		want     *endpoint.Endpoint
+	}{
+		{
+			name:     "FQDN, lowercase and default TTL",
+			endpoint: &endpoint.Endpoint{DNSName: "WWW.Test.com", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}},
+			want:     &endpoint.Endpoint{DNSName: "www.test.com.", RecordType: "A", RecordTTL: 300, Targets: endpoint.Targets{"1.2.3.4"}},
+		},
+		{
+			name:     "TTL below minimum",
+			endpoint: &endpoint.Endpoint{DNSName: "test.com.", RecordType: "A", RecordTTL: 10, Targets: endpoint.Targets{"1.2.3.4"}},
+			want:     &endpoint.Endpoint{DNSName: "test.com.", RecordType: "A", RecordTTL: 60, Targets: endpoint.Targets{"1.2.3.4"}},
+		},
+		{
+			name:     "TTL above maximum",
+			endpoint: &endpoint.Endpoint{DNSName: "test.com.", RecordType: "A", RecordTTL: 100000000, Targets: endpoint.Targets{"1.2.3.4"}},
+			want:     &endpoint.Endpoint{DNSName: "test.com.", RecordType: "A", RecordTTL: 99999999, Targets: endpoint.Targets{"1.2.3.4"}},
+		},
+		{
+			name:     "Duplicate targets",
+			endpoint: &endpoint.Endpoint{DNSName: "test.com.", RecordType: "A", RecordTTL: 300, Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8", "1.2.3.4"}},
+			want:     &endpoint.Endpoint{DNSName: "test.com.", RecordType: "A", RecordTTL: 300, Targets: endpoint.Targets{"1.2.3.4", "5.6.7.8"}},
+		},
+		{
+			name:     "CNAME target lowercase",
+			endpoint: &endpoint.Endpoint{DNSName: "test.com.", RecordType: "CNAME", RecordTTL: 300, Targets: endpoint.Targets{"Target.Example.com"}},
+			want:     &endpoint.Endpoint{DNSName: "test.com.", RecordType: "CNAME", RecordTTL: 300, Targets: endpoint.Targets{"target.example.com"}},
+		},
+		{
+			name:     "Long TXT target",
+			endpoint: &endpoint.Endpoint{DNSName: "test.com.", RecordType: "TXT", RecordTTL: 300, Targets: endpoint.Targets{longTXT}},
+			want:     &endpoint.Endpoint{DNSName: "test.com.", RecordType: "TXT", RecordTTL: 300, Targets: endpoint.Targets{`"` + longTXT + `"`}},
+		},
+		{
+			name:     "Short TXT target keeps case",
+			endpoint: &endpoint.Endpoint{DNSName: "test.com.", RecordType: "TXT", RecordTTL: 300, Targets: endpoint.Targets{`"Heritage=External-DNS"`}},
+			want:     &endpoint.Endpoint{DNSName: "test.com.", RecordType: "TXT", RecordTTL: 300, Targets: endpoint.Targets{`"Heritage=External-DNS"`}},
+		},
+	}
+
+	stackitDnsProvider := &StackitDNSProvider{logger: zap.NewNop()}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := stackitDnsProvider.AdjustEndpoints([]*endpoint.Endpoint{tt.endpoint})
+			assert.NoError(t, err)
+			assert.Equal(t, []*endpoint.Endpoint{tt.want}, got)
+		})
+	}
+}
+
+func TestAdjustEndpointsMatchesRecords(t *testing.T) {
+	t.Parallel()
+
+	stackitDnsProvider := &StackitDNSProvider{logger: zap.NewNop()}
+	longTXT := strings.Repeat("a", 300)
+
+	desired, err := stackitDnsProvider.AdjustEndpoints([]*endpoint.Endpoint{
+		{DNSName: "txt.test.com", RecordType: "TXT", Targets: endpoint.Targets{longTXT}},
+	})
+	assert.NoError(t, err)
+
+	// what STACKIT stores for the payload must be read back as the adjusted target
+	payload := getStackitRecordSetPayload(desired[0])
+	records := make([]stackitdnsclient.Record, 0, len(payload.Records))
+	for _, record := range payload.Records {
+		records = append(records, stackitdnsclient.Record{Content: record.Content})
+	}
+	current := endpointFromRecords(payload.Name, string(payload.Type), endpoint.TTL(*payload.Ttl), records)
+
+	assert.Equal(t, desired[0].Targets, current.Targets)
+	assert.Equal(t, desired[0].RecordTTL, current.RecordTTL)
+}
+
+func TestAdjustEndpointsComment(t *testing.T) {
+	t.Parallel()
+
+	stackitDnsProvider := &StackitDNSProvider{logger: zap.NewNop()}
+	stackitDnsProvider.comments.set([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("portal.test.com", "A", "1.2.3.4").WithProviderSpecific(commentProperty, "set in the portal"),
+	})
+
+	tests := []struct {
+		name        string
+		endpoint    *endpoint.Endpoint
+		wantComment string
+		wantOk      bool
+	}{
+		{"No annotation keeps the current comment", endpoint.NewEndpoint("Portal.test.com", "A", "1.2.3.4"), "set in the portal", true},
+		{"Annotation overrides the current comment", endpoint.NewEndpoint("portal.test.com", "A", "1.2.3.4").WithProviderSpecific(commentProperty, "owner: team-a"), "owner: team-a", true},
+		{"Empty annotation removes the current comment", endpoint.NewEndpoint("portal.test.com", "A", "1.2.3.4").WithProviderSpecific(commentProperty, ""), "", true},
+		{"Empty annotation without current comment", endpoint.NewEndpoint("www.test.com", "A", "1.2.3.4").WithProviderSpecific(commentProperty, ""), "", false},
+		{"No annotation without current comment", endpoint.NewEndpoint("www.test.com", "A", "1.2.3.4"), "", false},
+		{"Other record type", endpoint.NewEndpoint("portal.test.com", "AAAA", "::1"), "", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := stackitDnsProvider.AdjustEndpoints([]*endpoint.Endpoint{tt.endpoint})
+			assert.NoError(t, err)
+
+			comment, ok := got[0].GetProviderSpecificProperty(commentProperty)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantComment, comment)
+		})
+	}
+}
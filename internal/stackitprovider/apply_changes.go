@@ -4,23 +4,35 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/audit"
 )
 
 // ApplyChanges applies a given set of DNS changes to the STACKIT DNS API.
 // It enforces a strict phase-based execution order to prevent orphaned records
 // and mitigate quota limit issues (e.g., max 10k records per zone).
 // Deletions are processed before creations to free up zone quota.
-func (d *StackitDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+func (d *StackitDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) (err error) {
+	d.quarantine.retain(changes)
+	defer d.collectQuarantine()
+
 	if len(changes.Create)+len(changes.UpdateNew)+len(changes.Delete) == 0 {
 		return nil
 	}
 
+	ctx, span := d.tracer.Start(ctx, "stackitprovider.ApplyChanges", trace.WithAttributes(
+		attributeChanges.Int(len(changes.Create)+len(changes.UpdateNew)+len(changes.Delete)),
+	))
+	defer func() { endSpan(span, err) }()
+
 	d.logger.Info("records to delete", zap.String("records", fmt.Sprintf("%v", changes.Delete)))
 
 	zones, err := d.zoneFetcherClient.zones(ctx)
@@ -28,6 +40,55 @@ func (d *StackitDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Cha
 		return err
 	}
 
+	if err := d.checkDeletions(ctx, changes.Delete, zones); err != nil {
+		return err
+	}
+
+	var failures []*ChangeError
+	failed := map[string]bool{}
+	for _, batch := range d.buildBatches(changes) {
+		// Changes of records with a failed change are skipped, e.g. a record whose ownership record failed.
+		tasks, skipped := skipDependent(batch, failed)
+		failures = append(failures, skipped...)
+		tasks, quarantined := d.skipQuarantined(tasks)
+		// the changes depending on a quarantined change are held back like those of a failed change
+		for _, task := range quarantined {
+			failed[dependencyKey(task.change.DNSName, task.change.RecordType)] = true
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+
+		// If any batch fails (e.g., hitting a quota limit), the entire sync loop aborts.
+		// This leaves the DNS state consistent for the next retry attempt.
+		var batchFailures []*ChangeError
+		batchFailures, err = d.handleRRSetWithWorkers(ctx, tasks, zones)
+		failures = append(failures, batchFailures...)
+		if err != nil {
+			if len(failures) > 0 {
+				return errors.Join(err, &PartialFailureError{Failures: failures})
+			}
+
+			return err
+		}
+
+		for _, failure := range batchFailures {
+			failed[dependencyKey(failure.Name, failure.RecordType)] = true
+		}
+	}
+
+	if len(failures) > 0 {
+		// the failed changes are planned again by the next sync
+		return &PartialFailureError{Failures: failures}
+	}
+
+	d.collectSync(syncApplyChanges)
+
+	return nil
+}
+
+// buildBatches returns the non-empty batches of tasks for the changes in execution order.
+func (d *StackitDNSProvider) buildBatches(changes *plan.Changes) [][]changeTask {
 	// Separate ownership records (TXT) from target records (A, CNAME, etc.)
 	// to enforce strict dependency ordering and prevent orphaned records.
 	deleteTXT, deleteOther := splitTXTAndOther(changes.Delete)
@@ -41,25 +102,13 @@ func (d *StackitDNSProvider) ApplyChanges(ctx context.Context, changes *plan.Cha
 	batches := [][]changeTask{
 		d.buildRRSetTasks(deleteOther, DELETE),
 		d.buildRRSetTasks(deleteTXT, DELETE),
-		d.buildRRSetTasks(updateTXT, UPDATE),
-		d.buildRRSetTasks(updateOther, UPDATE),
+		withUpdateOld(d.buildRRSetTasks(updateTXT, UPDATE), changes.UpdateOld),
+		withUpdateOld(d.buildRRSetTasks(updateOther, UPDATE), changes.UpdateOld),
 		d.buildRRSetTasks(createTXT, CREATE),
 		d.buildRRSetTasks(createOther, CREATE),
 	}
 
-	for _, batch := range batches {
-		if len(batch) == 0 {
-			continue
-		}
-
-		// If any batch fails (e.g., hitting a quota limit), the entire sync loop aborts.
-		// This leaves the DNS state consistent for the next retry attempt.
-		if err := d.handleRRSetWithWorkers(ctx, batch, zones); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return slices.DeleteFunc(batches, func(batch []changeTask) bool { return len(batch) == 0 })
 }
 
 // splitTXTAndOther separates TXT records from all other record types.
@@ -97,22 +146,30 @@ func (d *StackitDNSProvider) buildRRSetTasks(
 // handleRRSetWithWorkers processes a batch of DNS changes concurrently.
 // It implements a fail-fast mechanism: if any worker encounters an error
 // (like a 4xx quota limit reached), it cancels the context to stop remaining queued tasks,
-// preventing an API DoS.
+// preventing an API DoS. In the partial failure mode, only errors affecting all changes cancel the batch, see
+// isAbortingError. Changes failed with other errors in this mode, and changes skipped because of a ConflictError in
+// any mode, do not cancel the batch and are returned as failures.
 func (d *StackitDNSProvider) handleRRSetWithWorkers(
 	ctx context.Context,
 	tasks []changeTask,
 	zones []stackitdnsclient.Zone,
-) error {
+) (failures []*ChangeError, err error) {
+	ctx, span := d.tracer.Start(ctx, "stackitprovider.handleRRSetWithWorkers", trace.WithAttributes(
+		attributeAction.String(tasks[0].action),
+		attributeChanges.Int(len(tasks)),
+	))
+	defer func() { endSpan(span, err) }()
+
 	cancelCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	workerChannel := make(chan changeTask, len(tasks))
-	errorChannel := make(chan error, len(tasks))
+	resultChannel := make(chan changeResult, len(tasks))
 
 	var wg sync.WaitGroup
 	for i := 0; i < d.workers; i++ {
 		wg.Add(1)
-		go d.changeWorker(cancelCtx, workerChannel, errorChannel, zones, &wg)
+		go d.changeWorker(cancelCtx, workerChannel, resultChannel, zones, &wg)
 	}
 
 	for _, task := range tasks {
@@ -122,21 +179,32 @@ func (d *StackitDNSProvider) handleRRSetWithWorkers(
 
 	var firstErr error
 	for i := 0; i < len(tasks); i++ {
-		err := <-errorChannel
-		if err != nil && firstErr == nil {
-			if !errors.Is(err, context.Canceled) {
-				firstErr = err
-				d.logger.Error("error encountered during batch processing, canceling remaining tasks", zap.Error(err))
-				// Fail fast: signal all active and pending workers to abort.
-				cancel()
-			}
+		result := <-resultChannel
+		d.recordQuarantine(result.task, result.err)
+		if result.err == nil || errors.Is(result.err, context.Canceled) {
+			continue
+		}
+
+		var conflictErr *ConflictError
+		if errors.As(result.err, &conflictErr) || (d.failureMode == FailureModePartial && !isAbortingError(result.err)) {
+			d.logger.Error("change failed, continuing with the remaining changes", zap.Error(result.err))
+			failures = append(failures, newChangeError(result.task, result.err))
+
+			continue
+		}
+
+		if firstErr == nil {
+			firstErr = result.err
+			d.logger.Error("error encountered during batch processing, canceling remaining tasks", zap.Error(result.err))
+			// Fail fast: signal all active and pending workers to abort.
+			cancel()
 		}
 	}
 
 	// wait until all workers have finished
 	wg.Wait()
 
-	return firstErr
+	return failures, firstErr
 }
 
 // changeWorker listens for tasks on the workerChannel and executes the appropriate API call.
@@ -144,7 +212,7 @@ func (d *StackitDNSProvider) handleRRSetWithWorkers(
 func (d *StackitDNSProvider) changeWorker(
 	ctx context.Context,
 	changes <-chan changeTask,
-	errorChannel chan<- error,
+	resultChannel chan<- changeResult,
 	zones []stackitdnsclient.Zone,
 	wg *sync.WaitGroup,
 ) {
@@ -153,21 +221,34 @@ func (d *StackitDNSProvider) changeWorker(
 	for change := range changes {
 		// Check for context cancellation before processing the next task.
 		if err := ctx.Err(); err != nil {
-			errorChannel <- err
+			resultChannel <- changeResult{task: change, err: err}
 
 			continue
 		}
 
+		if d.skipProtected(change.action, change.change) {
+			resultChannel <- changeResult{task: change}
+
+			continue
+		}
+
+		taskCtx, span := d.tracer.Start(ctx, "stackitprovider.changeWorker", trace.WithAttributes(
+			attributeAction.String(change.action),
+			attributeName.String(change.change.DNSName),
+			attributeRecordType.String(change.change.RecordType),
+		))
+
 		var err error
 		switch change.action {
 		case CREATE:
-			err = d.createRRSet(ctx, change.change, zones)
+			err = d.createRRSet(taskCtx, change.change, zones)
 		case UPDATE:
-			err = d.updateRRSet(ctx, change.change, zones)
+			err = d.updateRRSet(taskCtx, change.change, change.old, zones)
 		case DELETE:
-			err = d.deleteRRSet(ctx, change.change, zones)
+			err = d.deleteRRSet(taskCtx, change.change, zones)
 		}
-		errorChannel <- err
+		endSpan(span, err)
+		resultChannel <- changeResult{task: change, err: err}
 	}
 
 	d.logger.Debug("change worker finished")
@@ -178,104 +259,230 @@ func (d *StackitDNSProvider) createRRSet(
 	ctx context.Context,
 	change *endpoint.Endpoint,
 	zones []stackitdnsclient.Zone,
-) error {
+) (err error) {
 	resultZone, found := findBestMatchingZone(change.DNSName, zones)
 	if !found {
-		return fmt.Errorf("no matching zone found for %s", change.DNSName)
+		err := newNoMatchingZoneError(change.DNSName, zones)
+		d.logger.Error("no matching zone found", zap.String("name", change.DNSName), zap.Strings("candidates", err.candidates))
+
+		return err
 	}
 
+	setZoneAttributes(ctx, resultZone)
+
 	logFields := getLogFields(change, CREATE, resultZone.Id)
 	d.logger.Info("create record set", logFields...)
 
+	modifyChange(change)
+
+	rrSetPayload := getStackitRecordSetPayload(change)
+
+	auditEntry := &audit.Entry{
+		Action:     CREATE,
+		ZoneId:     resultZone.Id,
+		Name:       change.DNSName,
+		Type:       change.RecordType,
+		NewTargets: payloadContents(rrSetPayload.Records),
+		TTL:        int64(*rrSetPayload.Ttl),
+	}
+	defer func() { d.recordAudit(auditEntry, err) }()
+
 	if d.dryRun {
 		d.logger.Debug("dry run, skipping", logFields...)
 
 		return nil
 	}
 
-	modifyChange(change)
-
-	rrSetPayload := getStackitRecordSetPayload(change)
+	projectId, err := d.projects.owner(resultZone.Id)
+	if err != nil {
+		return err
+	}
 
 	// ignore all errors to just retry on next run
-	_, err := d.apiClient.DefaultAPI.CreateRecordSet(ctx, d.projectId, resultZone.Id).CreateRecordSetPayload(rrSetPayload).Execute()
+	resp, err := d.apiClient.Load().DefaultAPI.CreateRecordSet(ctx, projectId, resultZone.Id).CreateRecordSetPayload(rrSetPayload).Execute()
+	if err != nil && isAmbiguousError(err) {
+		resp, err = d.recoverAmbiguousCreate(ctx, projectId, resultZone.Id, change, rrSetPayload, err)
+	}
 	if err != nil {
 		d.logger.Error("error creating record set", zap.Error(err))
+		d.cache.invalidateRRSets(resultZone.Id)
 
 		return err
 	}
 
+	if resp != nil {
+		auditEntry.RecordSetId = resp.Rrset.Id
+		d.cache.upsertRRSet(resultZone.Id, resp.Rrset)
+	} else {
+		d.cache.invalidateRRSets(resultZone.Id)
+	}
+
 	d.logger.Info("create record set successfully", logFields...)
+	d.metrics.CollectChange(CREATE, change.RecordType, resultZone.DnsName)
 
 	return nil
 }
 
-// updateRRSet patches (overrides) contents in the record set in the stackitprovider.
+// recoverAmbiguousCreate handles a create call which failed without telling whether the record set was created.
+// Creates are not idempotent, so the call is only repeated once it is clear that the record set does not exist.
+func (d *StackitDNSProvider) recoverAmbiguousCreate(
+	ctx context.Context,
+	projectId string,
+	zoneId string,
+	change *endpoint.Endpoint,
+	payload stackitdnsclient.CreateRecordSetPayload,
+	createErr error,
+) (*stackitdnsclient.RecordSetResponse, error) {
+	d.logger.Warn("ambiguous response creating record set, checking whether it exists", zap.String("name", change.DNSName), zap.Error(createErr))
+
+	rrSets, err := d.rrSetFetcherClient.fetchRecords(ctx, zoneId, &change.DNSName)
+	if err != nil {
+		return nil, createErr
+	}
+
+	if rrSet, found := findRRSet(change.DNSName, change.RecordType, rrSets); found {
+		d.logger.Info("record set was created despite the ambiguous response", zap.String("name", change.DNSName))
+
+		return &stackitdnsclient.RecordSetResponse{Rrset: *rrSet}, nil
+	}
+
+	return d.apiClient.Load().DefaultAPI.CreateRecordSet(ctx, projectId, zoneId).CreateRecordSetPayload(payload).Execute()
+}
+
+// updateRRSet patches (overrides) contents in the record set in the stackitprovider. The record set is verified
+// against the live record set before it is patched, see verifyRRSet.
 func (d *StackitDNSProvider) updateRRSet(
 	ctx context.Context,
 	change *endpoint.Endpoint,
+	old *endpoint.Endpoint,
 	zones []stackitdnsclient.Zone,
-) error {
+) (err error) {
 	modifyChange(change)
 
-	resultZone, resultRRSet, err := d.rrSetFetcherClient.getRRSetForUpdateDeletion(ctx, change, zones)
+	resultZone, resultRRSet, cached, err := d.rrSetFetcherClient.getRRSetForUpdateDeletion(ctx, change, zones)
 	if err != nil {
 		return err
 	}
 
+	setZoneAttributes(ctx, resultZone)
+
 	logFields := getLogFields(change, UPDATE, resultRRSet.Id)
 	d.logger.Info("update record set", logFields...)
 
+	rrSet := getStackitPartialUpdateRecordSetPayload(change)
+
+	auditEntry := &audit.Entry{
+		Action:      UPDATE,
+		ZoneId:      resultZone.Id,
+		RecordSetId: resultRRSet.Id,
+		Name:        change.DNSName,
+		Type:        change.RecordType,
+		OldTargets:  recordContents(resultRRSet.Records),
+		NewTargets:  payloadContents(rrSet.Records),
+		TTL:         int64(rrSet.GetTtl()),
+	}
+	defer func() { d.recordAudit(auditEntry, err) }()
+
+	if err = d.verifyRRSet(ctx, UPDATE, old, resultZone, resultRRSet, cached); err != nil {
+		return err
+	}
+
 	if d.dryRun {
 		d.logger.Debug("dry run, skipping", logFields...)
 
 		return nil
 	}
 
-	rrSet := getStackitPartialUpdateRecordSetPayload(change)
+	projectId, err := d.projects.owner(resultZone.Id)
+	if err != nil {
+		return err
+	}
 
-	_, err = d.apiClient.DefaultAPI.PartialUpdateRecordSet(ctx, d.projectId, resultZone.Id, resultRRSet.Id).PartialUpdateRecordSetPayload(rrSet).Execute()
+	_, err = d.apiClient.Load().DefaultAPI.PartialUpdateRecordSet(ctx, projectId, resultZone.Id, resultRRSet.Id).PartialUpdateRecordSetPayload(rrSet).Execute()
 	if err != nil {
 		d.logger.Error("error updating record set", zap.Error(err))
+		d.cache.invalidateRRSets(resultZone.Id)
 
 		return err
 	}
 
+	d.cache.upsertRRSet(resultZone.Id, applyPartialUpdate(*resultRRSet, rrSet))
+
 	d.logger.Info("update record set successfully", logFields...)
+	d.metrics.CollectChange(UPDATE, change.RecordType, resultZone.DnsName)
 
 	return nil
 }
 
-// deleteRRSet deletes a record set in the stackitprovider for the given endpoint.
+// deleteRRSet deletes a record set in the stackitprovider for the given endpoint. The record set is verified against
+// the live record set before it is deleted, see verifyRRSet.
 func (d *StackitDNSProvider) deleteRRSet(
 	ctx context.Context,
 	change *endpoint.Endpoint,
 	zones []stackitdnsclient.Zone,
-) error {
+) (err error) {
 	modifyChange(change)
 
-	resultZone, resultRRSet, err := d.rrSetFetcherClient.getRRSetForUpdateDeletion(ctx, change, zones)
+	resultZone, resultRRSet, cached, err := d.rrSetFetcherClient.getRRSetForUpdateDeletion(ctx, change, zones)
 	if err != nil {
 		return err
 	}
 
+	setZoneAttributes(ctx, resultZone)
+
 	logFields := getLogFields(change, DELETE, resultRRSet.Id)
 	d.logger.Info("delete record set", logFields...)
 
+	auditEntry := &audit.Entry{
+		Action:      DELETE,
+		ZoneId:      resultZone.Id,
+		RecordSetId: resultRRSet.Id,
+		Name:        change.DNSName,
+		Type:        change.RecordType,
+		OldTargets:  recordContents(resultRRSet.Records),
+		TTL:         int64(resultRRSet.Ttl),
+	}
+	defer func() { d.recordAudit(auditEntry, err) }()
+
+	err = d.verifyRRSet(ctx, DELETE, nil, resultZone, resultRRSet, cached)
+	if errors.Is(err, errRRSetDeleted) {
+		// the record set was deleted out of band, like a retried delete finding it deleted by the previous attempt
+		d.logger.Info("record set already deleted", logFields...)
+
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	if d.dryRun {
 		d.logger.Debug("dry run, skipping", logFields...)
 
 		return nil
 	}
 
-	_, err = d.apiClient.DefaultAPI.DeleteRecordSet(ctx, d.projectId, resultZone.Id, resultRRSet.Id).Execute()
+	projectId, err := d.projects.owner(resultZone.Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.apiClient.Load().DefaultAPI.DeleteRecordSet(ctx, projectId, resultZone.Id, resultRRSet.Id).Execute()
+	if isNotFoundError(err) {
+		// a retried delete finds the record set already deleted by the previous attempt
+		d.logger.Info("record set already deleted", logFields...)
+		err = nil
+	}
 	if err != nil {
 		d.logger.Error("error deleting record set", zap.Error(err))
+		d.cache.invalidateRRSets(resultZone.Id)
 
 		return err
 	}
 
+	d.cache.deleteRRSet(resultZone.Id, resultRRSet.Id)
+
 	d.logger.Info("delete record set successfully", logFields...)
+	d.metrics.CollectChange(DELETE, change.RecordType, resultZone.DnsName)
 
 	return nil
 }
@@ -106,7 +106,9 @@ func TestNoMatchingZoneFound(t *testing.T) {
 	}
 
 	err = stackitDnsProvider.ApplyChanges(ctx, changes)
-	assert.Error(t, err)
+	var zoneErr *noMatchingZoneError
+	assert.ErrorAs(t, err, &zoneErr)
+	assert.Equal(t, []string{"test.com", "test2.com"}, zoneErr.candidates)
 }
 
 func TestNoRRSetFound(t *testing.T) {
@@ -245,6 +247,68 @@ func TestFailFastCancellation(t *testing.T) {
 	assert.Less(t, int(requestCount.Load()), 50, "expected fail-fast to cancel remaining requests")
 }
 
+func TestAmbiguousCreate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		existsAfterFail bool
+		expectedPosts   int32
+	}{
+		{"Record set exists after failed create", true, 1},
+		{"Record set missing after failed create", false, 2},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var posts atomic.Int32
+			mux := http.NewServeMux()
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			setUpCommonEndpoints(mux, getValidResponseZoneAllBytes(t), http.StatusOK)
+			mux.HandleFunc("/v1/projects/1234/zones/1234/rrsets", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					if tt.existsAfterFail {
+						getRrsetsResponseRecordsNonPaged(t, w, "test.com.", "1.2.3.4", "1234")
+
+						return
+					}
+					emptyResponse, err := json.Marshal(stackitdnsclient.ListRecordSetsResponse{
+						RrSets:     []stackitdnsclient.RecordSet{},
+						TotalPages: int32(1),
+					})
+					assert.NoError(t, err)
+					responseHandler(emptyResponse, http.StatusOK)(w, r)
+
+					return
+				}
+
+				if posts.Add(1) == 1 {
+					responseHandler([]byte(`{"message":"internal error"}`), http.StatusInternalServerError)(w, r)
+
+					return
+				}
+				responseHandler(getValidResponseRRSetAllBytes(t), http.StatusAccepted)(w, r)
+			})
+
+			stackitDnsProvider, err := getDefaultTestProvider(server)
+			assert.NoError(t, err)
+
+			err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+				Create: []*endpoint.Endpoint{
+					{DNSName: "test.com", Targets: endpoint.Targets{"1.2.3.4"}, RecordType: "A"},
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedPosts, posts.Load())
+		})
+	}
+}
+
 // setUpCommonEndpoints for all change types.
 func setUpCommonEndpoints(mux *http.ServeMux, responseZone []byte, responseZoneCode int) {
 	mux.HandleFunc("/v1/projects/1234/zones", func(w http.ResponseWriter, r *http.Request) {
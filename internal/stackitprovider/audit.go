@@ -0,0 +1,47 @@
+package stackitprovider
+
+import (
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.uber.org/zap"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/audit"
+)
+
+// recordAudit completes the audit entry of a mutation with its result and writes it. Failures to write the entry
+// are logged and do not fail the mutation.
+func (d *StackitDNSProvider) recordAudit(entry *audit.Entry, err error) {
+	entry.DryRun = d.dryRun
+	switch {
+	case err != nil:
+		entry.Result = audit.ResultFailure
+		entry.Error = err.Error()
+	case d.dryRun:
+		entry.Result = audit.ResultSkipped
+	default:
+		entry.Result = audit.ResultSuccess
+	}
+
+	if auditErr := d.auditLog.Record(*entry); auditErr != nil {
+		d.logger.Error("error writing audit entry", zap.Error(auditErr))
+	}
+}
+
+// recordContents returns the contents of the records of a record set.
+func recordContents(records []stackitdnsclient.Record) []string {
+	contents := make([]string, 0, len(records))
+	for i := range records {
+		contents = append(contents, records[i].Content)
+	}
+
+	return contents
+}
+
+// payloadContents returns the contents of the records of a payload.
+func payloadContents(records []stackitdnsclient.RecordPayload) []string {
+	contents := make([]string, 0, len(records))
+	for i := range records {
+		contents = append(contents, records[i].Content)
+	}
+
+	return contents
+}
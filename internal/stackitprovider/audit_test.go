@@ -0,0 +1,122 @@
+package stackitprovider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/audit"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestApplyChangesAuditLog(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{"Applied", false},
+		{"Dry run", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dryRun := tt.dryRun
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			oldRRSet, err := fakeServer.AddRecordSet(zone.Id, "old.example.com.", "A", 300, "1.1.1.1")
+			assert.NoError(t, err)
+			wwwRRSet, err := fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.1.1.1")
+			assert.NoError(t, err)
+
+			var buffer bytes.Buffer
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+				config.DryRun = dryRun
+				config.AuditLog = audit.New(&buffer)
+			})
+
+			err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+				Create:    []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("api.example.com", "TXT", 60, "text")},
+				UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.1.1.1")},
+				UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 120, "2.2.2.2")},
+				Delete:    []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("old.example.com", "A", 300, "1.1.1.1")},
+			})
+			assert.NoError(t, err)
+
+			result := audit.ResultSuccess
+			if dryRun {
+				result = audit.ResultSkipped
+			}
+
+			entries := readAuditEntries(t, &buffer)
+			assert.Len(t, entries, 3)
+
+			expected := []audit.Entry{
+				{Action: DELETE, ZoneId: zone.Id, RecordSetId: oldRRSet.Id, Name: "old.example.com.", Type: "A", OldTargets: []string{"1.1.1.1"}, TTL: 300},
+				{Action: UPDATE, ZoneId: zone.Id, RecordSetId: wwwRRSet.Id, Name: "www.example.com.", Type: "A", OldTargets: []string{"1.1.1.1"}, NewTargets: []string{"2.2.2.2"}, TTL: 120},
+				{Action: CREATE, ZoneId: zone.Id, Name: "api.example.com.", Type: "TXT", NewTargets: []string{"text"}, TTL: 60},
+			}
+			for i := range expected {
+				assert.False(t, entries[i].Timestamp.IsZero())
+				entries[i].Timestamp = expected[i].Timestamp
+
+				// the id of a created record set is only known once it is created
+				if expected[i].Action == CREATE && !dryRun {
+					assert.NotEmpty(t, entries[i].RecordSetId)
+					entries[i].RecordSetId = ""
+				}
+
+				expected[i].DryRun = dryRun
+				expected[i].Result = result
+				assert.Equal(t, expected[i], entries[i])
+			}
+		})
+	}
+}
+
+func TestApplyChangesAuditLogFailure(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	fakeServer.InjectFault(fake.QuotaFault(fake.OperationCreateRecordSet))
+
+	var buffer bytes.Buffer
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.AuditLog = audit.New(&buffer) })
+
+	err := stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("api.example.com", "A", "1.1.1.1")},
+	})
+	assert.Error(t, err)
+
+	entries := readAuditEntries(t, &buffer)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, zone.Id, entries[0].ZoneId)
+	assert.Equal(t, audit.ResultFailure, entries[0].Result)
+	assert.Equal(t, err.Error(), entries[0].Error)
+}
+
+func readAuditEntries(t *testing.T, buffer *bytes.Buffer) []audit.Entry {
+	t.Helper()
+
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(buffer)
+	for scanner.Scan() {
+		var entry audit.Entry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
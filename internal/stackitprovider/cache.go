@@ -0,0 +1,169 @@
+package stackitprovider
+
+import (
+	"sync"
+	"time"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+)
+
+// rrSetCache is an in-memory cache for zones and their record sets. Entries expire after the configured TTL
+// and are updated in place after successful create, update and delete calls. A nil *rrSetCache is a valid,
+// disabled cache: every lookup is a miss and every write is a no-op.
+type rrSetCache struct {
+	mu     sync.RWMutex
+	ttl    time.Duration
+	now    func() time.Time
+	zones  *cachedZones
+	rrSets map[string]*cachedRRSets
+}
+
+// cachedZones holds the cached zone list and its expiry.
+type cachedZones struct {
+	zones     []stackitdnsclient.Zone
+	expiresAt time.Time
+}
+
+// cachedRRSets holds the cached record sets of a single zone and their expiry.
+type cachedRRSets struct {
+	rrSets    []stackitdnsclient.RecordSet
+	expiresAt time.Time
+}
+
+// newRRSetCache returns a new rrSetCache or nil if the given ttl disables caching.
+func newRRSetCache(ttl time.Duration) *rrSetCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &rrSetCache{
+		ttl:    ttl,
+		now:    time.Now,
+		rrSets: make(map[string]*cachedRRSets),
+	}
+}
+
+// getZones returns the cached zones if they are present and not expired.
+func (c *rrSetCache) getZones() ([]stackitdnsclient.Zone, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.zones == nil || !c.now().Before(c.zones.expiresAt) {
+		return nil, false
+	}
+
+	return append([]stackitdnsclient.Zone(nil), c.zones.zones...), true
+}
+
+// setZones replaces the cached zones.
+func (c *rrSetCache) setZones(zones []stackitdnsclient.Zone) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.zones = &cachedZones{
+		zones:     append([]stackitdnsclient.Zone(nil), zones...),
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
+
+// getRRSets returns the cached record sets of a zone if they are present and not expired.
+func (c *rrSetCache) getRRSets(zoneId string) ([]stackitdnsclient.RecordSet, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.rrSets[zoneId]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return nil, false
+	}
+
+	return append([]stackitdnsclient.RecordSet(nil), entry.rrSets...), true
+}
+
+// setRRSets replaces the cached record sets of a zone.
+func (c *rrSetCache) setRRSets(zoneId string, rrSets []stackitdnsclient.RecordSet) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.rrSets[zoneId] = &cachedRRSets{
+		rrSets:    append([]stackitdnsclient.RecordSet(nil), rrSets...),
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
+
+// upsertRRSet inserts or replaces a record set (matched by id) in the cached record sets of a zone.
+// Nothing is cached if the record sets of the zone are not cached yet, since a single record set
+// does not represent the full zone.
+func (c *rrSetCache) upsertRRSet(zoneId string, rrSet stackitdnsclient.RecordSet) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.rrSets[zoneId]
+	if !ok {
+		return
+	}
+
+	for i := range entry.rrSets {
+		if entry.rrSets[i].Id == rrSet.Id {
+			entry.rrSets[i] = rrSet
+
+			return
+		}
+	}
+
+	entry.rrSets = append(entry.rrSets, rrSet)
+}
+
+// deleteRRSet removes a record set (matched by id) from the cached record sets of a zone.
+func (c *rrSetCache) deleteRRSet(zoneId, rrSetId string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.rrSets[zoneId]
+	if !ok {
+		return
+	}
+
+	for i := range entry.rrSets {
+		if entry.rrSets[i].Id == rrSetId {
+			entry.rrSets = append(entry.rrSets[:i], entry.rrSets[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// invalidateRRSets drops the cached record sets of a zone, forcing the next lookup to hit the API.
+func (c *rrSetCache) invalidateRRSets(zoneId string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.rrSets, zoneId)
+}
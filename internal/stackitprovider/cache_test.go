@@ -0,0 +1,161 @@
+package stackitprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestNewRRSetCacheDisabled(t *testing.T) {
+	t.Parallel()
+
+	cache := newRRSetCache(0)
+	assert.Nil(t, cache)
+
+	// a nil cache must be safe to use
+	cache.setZones([]stackitdnsclient.Zone{{Id: "1234"}})
+	cache.setRRSets("1234", []stackitdnsclient.RecordSet{{Id: "1"}})
+	cache.upsertRRSet("1234", stackitdnsclient.RecordSet{Id: "2"})
+	cache.deleteRRSet("1234", "1")
+	cache.invalidateRRSets("1234")
+
+	_, ok := cache.getZones()
+	assert.False(t, ok)
+	_, ok = cache.getRRSets("1234")
+	assert.False(t, ok)
+}
+
+func TestRRSetCacheExpiry(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := newRRSetCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.setZones([]stackitdnsclient.Zone{{Id: "1234"}})
+	cache.setRRSets("1234", []stackitdnsclient.RecordSet{{Id: "1"}})
+
+	zones, ok := cache.getZones()
+	assert.True(t, ok)
+	assert.Len(t, zones, 1)
+	rrSets, ok := cache.getRRSets("1234")
+	assert.True(t, ok)
+	assert.Len(t, rrSets, 1)
+
+	now = now.Add(time.Minute)
+
+	_, ok = cache.getZones()
+	assert.False(t, ok)
+	_, ok = cache.getRRSets("1234")
+	assert.False(t, ok)
+}
+
+func TestRRSetCacheWriteThrough(t *testing.T) {
+	t.Parallel()
+
+	cache := newRRSetCache(time.Minute)
+
+	// record sets of unknown zones are not cached partially
+	cache.upsertRRSet("5678", stackitdnsclient.RecordSet{Id: "1"})
+	_, ok := cache.getRRSets("5678")
+	assert.False(t, ok)
+
+	cache.setRRSets("1234", []stackitdnsclient.RecordSet{
+		{Id: "1", Name: "a.test.com.", Ttl: 300},
+		{Id: "2", Name: "b.test.com.", Ttl: 300},
+	})
+
+	cache.upsertRRSet("1234", stackitdnsclient.RecordSet{Id: "1", Name: "a.test.com.", Ttl: 600})
+	cache.upsertRRSet("1234", stackitdnsclient.RecordSet{Id: "3", Name: "c.test.com.", Ttl: 300})
+	cache.deleteRRSet("1234", "2")
+
+	rrSets, ok := cache.getRRSets("1234")
+	assert.True(t, ok)
+	assert.Equal(t, []stackitdnsclient.RecordSet{
+		{Id: "1", Name: "a.test.com.", Ttl: 600},
+		{Id: "3", Name: "c.test.com.", Ttl: 300},
+	}, rrSets)
+
+	cache.invalidateRRSets("1234")
+	_, ok = cache.getRRSets("1234")
+	assert.False(t, ok)
+}
+
+func TestRecordsCached(t *testing.T) {
+	t.Parallel()
+
+	var zoneRequests, rrSetRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/1234/zones", func(w http.ResponseWriter, r *http.Request) {
+		zoneRequests.Add(1)
+		getZonesResponseRecordsNonPaged(t, w)
+	})
+	mux.HandleFunc("/v1/projects/1234/zones/1234/rrsets", func(w http.ResponseWriter, r *http.Request) {
+		rrSetRequests.Add(1)
+		getRrsetsResponseRecordsNonPaged(t, w, "test.com.", "1.2.3.4", "1234")
+	})
+	mux.HandleFunc("/v1/projects/1234/zones/5678/rrsets", func(w http.ResponseWriter, r *http.Request) {
+		rrSetRequests.Add(1)
+		getRrsetsResponseRecordsNonPaged(t, w, "test2.com.", "5.6.7.8", "5678")
+	})
+	mux.HandleFunc("/v1/projects/1234/zones/1234/rrsets/1234", responseHandler(nil, http.StatusOK))
+
+	stackitDnsProvider, err := getCachedTestProvider(server)
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		endpoints, err := stackitDnsProvider.Records(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, endpoints, 2)
+	}
+
+	assert.Equal(t, int32(1), zoneRequests.Load())
+	assert.Equal(t, int32(2), rrSetRequests.Load())
+
+	// updates are resolved from the cache and written through to it
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{
+			{DNSName: "test.com", Targets: endpoint.Targets{"4.3.2.1"}, RecordType: "A"},
+		},
+	})
+	assert.NoError(t, err)
+
+	endpoints, err := stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.Equal(t, "4.3.2.1", endpoints[0].Targets[0])
+
+	assert.Equal(t, int32(1), zoneRequests.Load())
+	assert.Equal(t, int32(2), rrSetRequests.Load())
+}
+
+func getCachedTestProvider(server *httptest.Server) (*StackitDNSProvider, error) {
+	return NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{
+			ProjectIds:   []string{"1234"},
+			DomainFilter: endpoint.DomainFilter{},
+			DryRun:       false,
+			Workers:      1,
+			CacheEnabled: true,
+			CacheTTL:     time.Minute,
+		},
+		stackitconfig.WithHTTPClient(server.Client()),
+		stackitconfig.WithEndpoint(server.URL),
+		// we need a non-empty token for the bootstrapping not to fail
+		stackitconfig.WithToken("token"))
+}
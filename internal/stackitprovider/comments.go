@@ -0,0 +1,59 @@
+package stackitprovider
+
+import (
+	"sync"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// commentKey identifies the record set of a comment.
+type commentKey struct {
+	name       string
+	recordType string
+}
+
+// newCommentKey returns the key of the record set of an endpoint.
+func newCommentKey(ep *endpoint.Endpoint) commentKey {
+	return commentKey{name: normalizeDNSName(ep.DNSName), recordType: ep.RecordType}
+}
+
+// recordComments holds the comments of the record sets returned by the last call of Records. external-dns calls
+// AdjustEndpoints after Records, so the comments are used to align the comment property of the desired endpoints
+// with the current record sets.
+type recordComments struct {
+	mu       sync.Mutex
+	comments map[commentKey]string
+}
+
+// set replaces the comments with those of the given current endpoints.
+func (c *recordComments) set(endpoints []*endpoint.Endpoint) {
+	comments := make(map[commentKey]string)
+	for _, ep := range endpoints {
+		if comment, ok := ep.GetProviderSpecificProperty(commentProperty); ok {
+			comments[newCommentKey(ep)] = comment
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.comments = comments
+}
+
+// align sets the comment property of a desired endpoint to the form in which Records returns it, as long as this
+// does not change the comment the record set ends up with. A desired endpoint without the property keeps the current
+// comment on updates, so it gets the current comment. An empty property removes the comment, so it is dropped if the
+// record set has no comment, just like Records drops empty comments.
+func (c *recordComments) align(ep *endpoint.Endpoint) {
+	c.mu.Lock()
+	current, hasCurrent := c.comments[newCommentKey(ep)]
+	c.mu.Unlock()
+
+	desired, hasDesired := ep.GetProviderSpecificProperty(commentProperty)
+	switch {
+	case !hasDesired && hasCurrent:
+		ep.SetProviderSpecificProperty(commentProperty, current)
+	case hasDesired && desired == "" && !hasCurrent:
+		ep.DeleteProviderSpecificProperty(commentProperty)
+	}
+}
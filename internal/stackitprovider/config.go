@@ -1,11 +1,61 @@
 package stackitprovider
 
-import "sigs.k8s.io/external-dns/endpoint"
+import (
+	"time"
+
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/audit"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics"
+)
 
 // Config is used to configure the creation of the StackitDNSProvider.
 type Config struct {
-	ProjectId    string
-	DomainFilter endpoint.DomainFilter
-	DryRun       bool
-	Workers      int
+	// ProjectIds are the STACKIT projects whose zones are managed.
+	ProjectIds []string
+	// DomainProjects maps domains to the STACKIT projects owning them. The zones of a project named here are
+	// restricted to its domains. Projects only named here are managed as well.
+	DomainProjects map[string]string
+	DomainFilter   endpoint.DomainFilter
+	DryRun         bool
+	Workers        int
+	// DeletionGuard limits the record sets deleted in a zone by a single change set.
+	DeletionGuard DeletionGuard
+	// ProtectedRecords are record sets which are never changed.
+	ProtectedRecords ProtectedRecords
+	// ConflictPolicy decides what happens to an update of a record set which was changed since external-dns read it.
+	// Updates are not checked if it is empty.
+	ConflictPolicy ConflictPolicy
+	// OptimisticConcurrency re-reads every record set just before it is patched or deleted and skips the change if
+	// the record set differs from the state the change is based on.
+	OptimisticConcurrency bool
+	// FailureMode decides how a change set continues once a change failed. It fails fast if empty.
+	FailureMode FailureMode
+	// Quarantine quarantines record sets whose changes failed repeatedly.
+	Quarantine QuarantinePolicy
+	// CacheEnabled enables the in-memory cache for zones and record sets.
+	CacheEnabled bool
+	// CacheTTL is the duration after which cached zones and record sets are fetched again.
+	CacheTTL time.Duration
+	// Metrics collects the metrics of the managed zones and records. No metrics are collected if it is nil.
+	Metrics metrics.ProviderMetrics
+	// AuditLog records every mutation of a record set. No audit entries are written if it is nil.
+	AuditLog *audit.Log
+}
+
+// DefaultConfig returns the configuration the webhook runs with if no parameters are given. The command line
+// parameters default to its values.
+func DefaultConfig() Config {
+	return Config{
+		Workers:               10,
+		ConflictPolicy:        ConflictPolicyFlag,
+		OptimisticConcurrency: true,
+		FailureMode:           FailureModeFailFast,
+		Quarantine: QuarantinePolicy{
+			Backoff:    5 * time.Minute,
+			MaxBackoff: time.Hour,
+		},
+		CacheEnabled: false,
+		CacheTTL:     5 * time.Minute,
+	}
 }
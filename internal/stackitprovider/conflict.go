@@ -0,0 +1,237 @@
+package stackitprovider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ConflictPolicy decides what happens to an update if the record set was changed since external-dns read it, i.e.
+// if the live record set differs from the old state external-dns sent with the update.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyIgnore applies updates without comparing the record set.
+	ConflictPolicyIgnore ConflictPolicy = "ignore"
+	// ConflictPolicyFlag applies the update, but logs and counts the conflict.
+	ConflictPolicyFlag ConflictPolicy = "flag"
+	// ConflictPolicyRefuse refuses the update with an error.
+	ConflictPolicyRefuse ConflictPolicy = "refuse"
+)
+
+// ConflictPolicies are all supported conflict policies.
+var ConflictPolicies = []ConflictPolicy{ConflictPolicyIgnore, ConflictPolicyFlag, ConflictPolicyRefuse}
+
+// updateKey identifies the record set of an update in UpdateOld and UpdateNew.
+type updateKey struct {
+	name          string
+	recordType    string
+	setIdentifier string
+}
+
+// newUpdateKey returns the key of the record set of an update.
+func newUpdateKey(ep *endpoint.Endpoint) updateKey {
+	return updateKey{
+		name:          normalizeDNSName(ep.DNSName),
+		recordType:    ep.RecordType,
+		setIdentifier: ep.SetIdentifier,
+	}
+}
+
+// withUpdateOld sets the state external-dns based the update tasks on from the matching endpoints of UpdateOld.
+func withUpdateOld(tasks []changeTask, updateOld []*endpoint.Endpoint) []changeTask {
+	olds := make(map[updateKey]*endpoint.Endpoint, len(updateOld))
+	for _, old := range updateOld {
+		olds[newUpdateKey(old)] = old
+	}
+
+	for i := range tasks {
+		tasks[i].old = olds[newUpdateKey(tasks[i].change)]
+	}
+
+	return tasks
+}
+
+// rrSetConflicts are the differences of the live record set to the state a mutation is based on.
+type rrSetConflicts struct {
+	// concurrent is the difference to the base record set the mutation resolved, if optimistic concurrency is
+	// enabled.
+	concurrent error
+	// updateOld is the difference to the old state of an update, if the conflict policy checks it.
+	updateOld error
+	// deleted is whether the record set of a delete does not exist anymore, so there is nothing left to delete.
+	deleted bool
+}
+
+// refused returns the conflict for which the mutation is skipped, if any.
+func (c rrSetConflicts) refused(policy ConflictPolicy) error {
+	if c.concurrent != nil {
+		return c.concurrent
+	}
+
+	if c.updateOld != nil && policy == ConflictPolicyRefuse {
+		return c.updateOld
+	}
+
+	return nil
+}
+
+// findConflicts compares the record set a mutation is based on with the live record set. With optimistic
+// concurrency, the live record set is fetched and compared with the base record set the mutation resolved. The old
+// state of an update is compared with the live record set according to the conflict policy. The live record set is
+// only fetched if the base record set was taken from the cache, a base record set just fetched from the API is used as
+// live record set. A delete of a record set which does not exist anymore is no conflict, it is reported as deleted
+// instead.
+func (d *StackitDNSProvider) findConflicts(
+	ctx context.Context,
+	action string,
+	old *endpoint.Endpoint,
+	zone *stackitdnsclient.Zone,
+	base *stackitdnsclient.RecordSet,
+	cached bool,
+) (rrSetConflicts, error) {
+	checkOld := action == UPDATE && old != nil && d.conflictPolicy != "" && d.conflictPolicy != ConflictPolicyIgnore
+	if !checkOld && !d.optimisticConcurrency {
+		return rrSetConflicts{}, nil
+	}
+
+	live := base
+	if cached {
+		liveRRSet, found, err := d.rrSetFetcherClient.getLiveRRSet(ctx, zone.Id, base.Name, string(base.Type))
+		if err != nil {
+			return rrSetConflicts{}, err
+		}
+
+		live = nil
+		if found {
+			live = liveRRSet
+		}
+
+		// the cache is refreshed with the live record set, so that the next sync resolves its changes against the
+		// current state instead of conflicting again until the cache expires
+		if live == nil || live.Id != base.Id {
+			d.cache.deleteRRSet(zone.Id, base.Id)
+		}
+		if live != nil {
+			d.cache.upsertRRSet(zone.Id, *live)
+		}
+	}
+
+	if action == DELETE && live == nil {
+		return rrSetConflicts{deleted: true}, nil
+	}
+
+	var conflicts rrSetConflicts
+	if d.optimisticConcurrency {
+		conflicts.concurrent = compareRRSets(base, live)
+	}
+	if checkOld {
+		conflicts.updateOld = compareUpdateOld(old, live)
+	}
+
+	return conflicts, nil
+}
+
+// verifyRRSet checks the record set a mutation is based on just before the mutation is issued, see findConflicts. A
+// concurrent change of the record set is returned as ConflictError. A difference to the old state of an update is
+// handled according to the conflict policy. errRRSetDeleted is returned for a delete of a record set which does not
+// exist anymore.
+func (d *StackitDNSProvider) verifyRRSet(
+	ctx context.Context,
+	action string,
+	old *endpoint.Endpoint,
+	zone *stackitdnsclient.Zone,
+	base *stackitdnsclient.RecordSet,
+	cached bool,
+) error {
+	conflicts, err := d.findConflicts(ctx, action, old, zone, base, cached)
+	if err != nil {
+		return err
+	}
+
+	if conflicts.deleted {
+		return errRRSetDeleted
+	}
+
+	if conflicts.concurrent != nil {
+		d.logger.Warn("record set changed concurrently, skipping the change", zap.String("action", action), zap.Error(conflicts.concurrent))
+
+		return conflicts.concurrent
+	}
+
+	if conflicts.updateOld == nil {
+		return nil
+	}
+
+	d.metrics.CollectUpdateConflict(zone.DnsName, string(d.conflictPolicy))
+
+	if d.conflictPolicy == ConflictPolicyFlag {
+		d.logger.Warn("record set changed since it was read, updating it anyway", zap.Error(conflicts.updateOld))
+
+		return nil
+	}
+
+	d.logger.Error("record set changed since it was read, refusing the update", zap.Error(conflicts.updateOld))
+
+	return conflicts.updateOld
+}
+
+// compareRRSets returns a ConflictError if the live record set, nil if it does not exist, is not the base record set
+// or differs from it in TTL or records.
+func compareRRSets(base, live *stackitdnsclient.RecordSet) error {
+	reason := ""
+	switch {
+	case live == nil:
+		reason = "the record set does not exist anymore"
+	case live.Id != base.Id:
+		reason = "the record set was replaced"
+	case live.Ttl != base.Ttl:
+		reason = fmt.Sprintf("ttl is %d instead of %d", live.Ttl, base.Ttl)
+	default:
+		liveRecords, baseRecords := recordContents(live.Records), recordContents(base.Records)
+		slices.Sort(liveRecords)
+		slices.Sort(baseRecords)
+		if !slices.Equal(liveRecords, baseRecords) {
+			reason = fmt.Sprintf("records are %q instead of %q", liveRecords, baseRecords)
+		}
+	}
+
+	if reason == "" {
+		return nil
+	}
+
+	return &ConflictError{Name: base.Name, RecordType: string(base.Type), Reason: reason}
+}
+
+// compareUpdateOld returns a ConflictError if the live record set, nil if it does not exist, differs from the old
+// state of an update. The TTL is only compared if the old state has one.
+func compareUpdateOld(old *endpoint.Endpoint, live *stackitdnsclient.RecordSet) error {
+	if live == nil {
+		return &ConflictError{Name: old.DNSName, RecordType: old.RecordType, Reason: "the record set does not exist anymore"}
+	}
+
+	liveEndpoint := endpointFromRecords(live.Name, string(live.Type), endpoint.TTL(live.Ttl), live.Records)
+
+	if old.RecordTTL != 0 && old.RecordTTL != liveEndpoint.RecordTTL {
+		return &ConflictError{
+			Name:       old.DNSName,
+			RecordType: old.RecordType,
+			Reason:     fmt.Sprintf("ttl is %d instead of %d", liveEndpoint.RecordTTL, old.RecordTTL),
+		}
+	}
+
+	liveTargets := normalizeTargets(old.RecordType, liveEndpoint.Targets)
+	if !liveTargets.Same(normalizeTargets(old.RecordType, old.Targets)) {
+		return &ConflictError{
+			Name:       old.DNSName,
+			RecordType: old.RecordType,
+			Reason:     fmt.Sprintf("targets are %q instead of %q", []string(liveEndpoint.Targets), []string(old.Targets)),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,403 @@
+package stackitprovider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestCompareUpdateOld(t *testing.T) {
+	t.Parallel()
+
+	live := &stackitdnsclient.RecordSet{
+		Name:    "txt.example.com.",
+		Type:    "TXT",
+		Ttl:     300,
+		Records: []stackitdnsclient.Record{{Content: `"v=spf1 -all"`}, {Content: `"other"`}},
+	}
+
+	tests := []struct {
+		name    string
+		old     *endpoint.Endpoint
+		live    *stackitdnsclient.RecordSet
+		wantErr string
+	}{
+		{"Unchanged", endpoint.NewEndpointWithTTL("txt.example.com", "TXT", 300, `"other"`, `"v=spf1 -all"`), live, ""},
+		{"Unknown TTL", endpoint.NewEndpoint("txt.example.com", "TXT", `"v=spf1 -all"`, `"other"`), live, ""},
+		{
+			"TTL changed",
+			endpoint.NewEndpointWithTTL("txt.example.com", "TXT", 60, `"v=spf1 -all"`, `"other"`),
+			live,
+			`record set "txt.example.com" of type TXT changed since it was read: ttl is 300 instead of 60`,
+		},
+		{
+			"Targets changed",
+			endpoint.NewEndpointWithTTL("txt.example.com", "TXT", 300, `"v=spf1 -all"`),
+			live,
+			`record set "txt.example.com" of type TXT changed since it was read: targets are ["\"v=spf1 -all\"" "\"other\""] instead of ["\"v=spf1 -all\""]`,
+		},
+		{
+			"Deleted",
+			endpoint.NewEndpointWithTTL("txt.example.com", "TXT", 300, `"v=spf1 -all"`),
+			nil,
+			`record set "txt.example.com" of type TXT changed since it was read: the record set does not exist anymore`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := compareUpdateOld(tt.old, tt.live)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestApplyChangesUpdateConflict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		policy      ConflictPolicy
+		wantErr     bool
+		wantUpdated bool
+	}{
+		{"Ignore", ConflictPolicyIgnore, false, true},
+		{"Flag", ConflictPolicyFlag, false, true},
+		{"Refuse", ConflictPolicyRefuse, true, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			_, err := fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.1.1.1")
+			assert.NoError(t, err)
+
+			providerMetrics := mockmetrics.NewMockProviderMetrics(ctrl)
+			providerMetrics.EXPECT().CollectAPICall(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			providerMetrics.EXPECT().CollectChange(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			providerMetrics.EXPECT().SetZones(gomock.Any()).AnyTimes()
+			providerMetrics.EXPECT().SetRecords(gomock.Any(), gomock.Any()).AnyTimes()
+			providerMetrics.EXPECT().SetLastSuccessfulSync(gomock.Any(), gomock.Any()).AnyTimes()
+			if tt.policy != ConflictPolicyIgnore {
+				providerMetrics.EXPECT().CollectUpdateConflict("example.com", string(tt.policy)).Times(1)
+			}
+
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+				config.ConflictPolicy = tt.policy
+				config.CacheEnabled = true
+				config.CacheTTL = time.Hour
+				config.Metrics = providerMetrics
+			})
+
+			// external-dns reads the record set, which is cached
+			_, err = stackitDnsProvider.Records(context.Background())
+			assert.NoError(t, err)
+
+			// another writer changes it in the meantime
+			otherProvider := getFakeTestProvider(t, fakeServer, nil)
+			err = otherProvider.ApplyChanges(context.Background(), &plan.Changes{
+				UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "3.3.3.3")},
+			})
+			assert.NoError(t, err)
+
+			err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.1.1.1")},
+				UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2")},
+			})
+			if tt.wantErr {
+				assert.ErrorContains(t, err, `record set "www.example.com" of type A changed since it was read: targets are ["3.3.3.3"] instead of ["1.1.1.1"]`)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			expected := "3.3.3.3"
+			if tt.wantUpdated {
+				expected = "2.2.2.2"
+			}
+			rrSets := fakeServer.RecordSets(zone.Id)
+			assert.Len(t, rrSets, 1)
+			assert.Equal(t, expected, rrSets[0].Records[0].Content)
+		})
+	}
+}
+
+func TestPlanChangesUpdateConflict(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		policy       ConflictPolicy
+		wantConflict string
+	}{
+		{"Flag", ConflictPolicyFlag, ""},
+		{"Refuse", ConflictPolicyRefuse, `record set "www.example.com" of type A changed since it was read: targets are ["3.3.3.3"] instead of ["1.1.1.1"]`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			_, err := fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "3.3.3.3")
+			assert.NoError(t, err)
+
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.ConflictPolicy = tt.policy })
+
+			changePlan, err := stackitDnsProvider.PlanChanges(context.Background(), &plan.Changes{
+				UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.1.1.1")},
+				UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2")},
+			})
+			assert.NoError(t, err)
+			assert.Len(t, changePlan.Operations, 1)
+			assert.Equal(t, tt.wantConflict, changePlan.Operations[0].Conflict)
+			if tt.wantConflict != "" {
+				assert.Equal(t, 1, changePlan.Conflicts)
+			}
+		})
+	}
+}
+
+func TestCompareRRSets(t *testing.T) {
+	t.Parallel()
+
+	base := &stackitdnsclient.RecordSet{
+		Id:      "rrset-1",
+		Name:    "www.example.com.",
+		Type:    "A",
+		Ttl:     300,
+		Records: []stackitdnsclient.Record{{Content: "1.1.1.1"}, {Content: "2.2.2.2"}},
+	}
+
+	changed := func(change func(rrSet *stackitdnsclient.RecordSet)) *stackitdnsclient.RecordSet {
+		rrSet := *base
+		change(&rrSet)
+
+		return &rrSet
+	}
+
+	tests := []struct {
+		name    string
+		live    *stackitdnsclient.RecordSet
+		wantErr string
+	}{
+		{"Unchanged", changed(func(*stackitdnsclient.RecordSet) {}), ""},
+		{
+			"Records reordered",
+			changed(func(rrSet *stackitdnsclient.RecordSet) {
+				rrSet.Records = []stackitdnsclient.Record{{Content: "2.2.2.2"}, {Content: "1.1.1.1"}}
+			}),
+			"",
+		},
+		{"Deleted", nil, "the record set does not exist anymore"},
+		{"Replaced", changed(func(rrSet *stackitdnsclient.RecordSet) { rrSet.Id = "rrset-2" }), "the record set was replaced"},
+		{"TTL changed", changed(func(rrSet *stackitdnsclient.RecordSet) { rrSet.Ttl = 60 }), "ttl is 60 instead of 300"},
+		{
+			"Records changed",
+			changed(func(rrSet *stackitdnsclient.RecordSet) {
+				rrSet.Records = []stackitdnsclient.Record{{Content: "1.1.1.1"}}
+			}),
+			`records are ["1.1.1.1"] instead of ["1.1.1.1" "2.2.2.2"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := compareRRSets(base, tt.live)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var conflictErr *ConflictError
+			assert.ErrorAs(t, err, &conflictErr)
+			assert.Equal(t, "www.example.com.", conflictErr.Name)
+			assert.Equal(t, "A", conflictErr.RecordType)
+			assert.Equal(t, tt.wantErr, conflictErr.Reason)
+		})
+	}
+}
+
+func TestApplyChangesOptimisticConcurrency(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	for _, name := range []string{"www.example.com.", "gone.example.com.", "old.example.com."} {
+		_, err := fakeServer.AddRecordSet(zone.Id, name, "A", 300, "1.1.1.1")
+		assert.NoError(t, err)
+	}
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+		config.Workers = 2
+		config.OptimisticConcurrency = true
+		config.CacheEnabled = true
+		config.CacheTTL = time.Hour
+	})
+
+	// the record sets are resolved against the cache
+	_, err := stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+
+	// another writer changes and deletes record sets in the meantime
+	otherProvider := getFakeTestProvider(t, fakeServer, nil)
+	err = otherProvider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "3.3.3.3")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("gone.example.com", "A", "1.1.1.1")},
+	})
+	assert.NoError(t, err)
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create:    []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("new.example.com", "A", 300, "4.4.4.4")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2")},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("gone.example.com", "A", "1.1.1.1"),
+			endpoint.NewEndpoint("old.example.com", "A", "1.1.1.1"),
+		},
+	})
+	var conflictErr *ConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.ErrorContains(t, err, `record set "www.example.com." of type A changed since it was read: records are ["3.3.3.3"] instead of ["1.1.1.1"]`)
+	var partialErr *PartialFailureError
+	assert.ErrorAs(t, err, &partialErr)
+	// the record set deleted in the meantime is no conflict, there is nothing left to delete
+	assert.Len(t, partialErr.Failures, 1)
+
+	// only the conflicting change was skipped
+	contents := map[string]string{}
+	for _, rrSet := range fakeServer.RecordSets(zone.Id) {
+		contents[rrSet.Name] = rrSet.Records[0].Content
+	}
+	assert.Equal(t, map[string]string{"www.example.com.": "3.3.3.3", "new.example.com.": "4.4.4.4"}, contents)
+	assert.Equal(t, 1, fakeServer.Requests(fake.OperationPartialUpdateRecordSet))
+	assert.Equal(t, 2, fakeServer.Requests(fake.OperationDeleteRecordSet))
+
+	// the cache was refreshed with the live record sets, so the next sync sees the current state
+	endpoints, err := stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+	targets := map[string]endpoint.Targets{}
+	for _, ep := range endpoints {
+		targets[ep.DNSName] = ep.Targets
+	}
+	assert.Equal(t, map[string]endpoint.Targets{
+		"www.example.com": {"3.3.3.3"},
+		"new.example.com": {"4.4.4.4"},
+	}, targets)
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fakeServer.Requests(fake.OperationPartialUpdateRecordSet))
+}
+
+func TestApplyChangesOptimisticConcurrencyWithoutCache(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	_, err := fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.OptimisticConcurrency = true })
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2")},
+	})
+	assert.NoError(t, err)
+
+	// the record set just fetched for the update is the live record set, it is not fetched a second time
+	assert.Equal(t, 1, fakeServer.Requests(fake.OperationListRecordSets))
+	assert.Equal(t, 1, fakeServer.Requests(fake.OperationPartialUpdateRecordSet))
+}
+
+func TestPlanChangesOptimisticConcurrency(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	for _, name := range []string{"www.example.com.", "gone.example.com."} {
+		_, err := fakeServer.AddRecordSet(zone.Id, name, "A", 300, "1.1.1.1")
+		assert.NoError(t, err)
+	}
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+		config.OptimisticConcurrency = true
+		config.CacheEnabled = true
+		config.CacheTTL = time.Hour
+	})
+
+	// the record set is resolved against the cache
+	_, err := stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+
+	otherProvider := getFakeTestProvider(t, fakeServer, nil)
+	err = otherProvider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "3.3.3.3")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("gone.example.com", "A", "1.1.1.1")},
+	})
+	assert.NoError(t, err)
+
+	changePlan, err := stackitDnsProvider.PlanChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("gone.example.com", "A", "1.1.1.1")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, changePlan.Conflicts)
+	assert.Len(t, changePlan.Operations, 2)
+
+	operations := map[string]report.PlannedOperation{}
+	for _, operation := range changePlan.Operations {
+		operations[operation.Name] = operation
+	}
+	assert.Equal(t,
+		`record set "www.example.com." of type A changed since it was read: records are ["3.3.3.3"] instead of ["1.1.1.1"]`,
+		operations["www.example.com."].Conflict,
+	)
+	// the record set deleted in the meantime needs no delete
+	assert.Equal(t, "record set already deleted", operations["gone.example.com."].Skipped)
+	assert.Empty(t, operations["gone.example.com."].Conflict)
+}
+
+func TestNewStackitDNSProviderConflictPolicy(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{ProjectIds: []string{"1234"}, ConflictPolicy: "merge"},
+		stackitconfig.WithToken("token"),
+	)
+	assert.EqualError(t, err, `unsupported conflict policy "merge", supported policies: [ignore flag refuse]`)
+}
@@ -0,0 +1,156 @@
+package stackitprovider
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// DeletionLimit limits the number of record sets deleted in a zone by a single change set.
+type DeletionLimit struct {
+	// Count is the maximum number of deleted record sets. The limit is disabled if it is 0.
+	Count int
+	// Percent is the maximum percentage of the record sets of the zone which are deleted. The limit is disabled if
+	// it is 0.
+	Percent float64
+}
+
+// enabled returns whether any of the limits is set.
+func (l DeletionLimit) enabled() bool {
+	return l.Count > 0 || l.Percent > 0
+}
+
+// DeletionGuard protects the zones against the deletion of many record sets at once, e.g. after a misconfigured
+// source or domain filter of external-dns.
+type DeletionGuard struct {
+	// Default is the limit of all zones without a limit of their own.
+	Default DeletionLimit
+	// Zones are the limits of single zones by their DNS name.
+	Zones map[string]DeletionLimit
+	// Override applies change sets exceeding the limits, e.g. for an intentional cleanup. The exceeded limits are
+	// still logged.
+	Override bool
+}
+
+// limit returns the deletion limit of the zone with the given DNS name.
+func (g DeletionGuard) limit(zoneName string) DeletionLimit {
+	for name, limit := range g.Zones {
+		if normalizeDNSName(name) == normalizeDNSName(zoneName) {
+			return limit
+		}
+	}
+
+	return g.Default
+}
+
+// checkDeletions refuses the deletions if they exceed the deletion limit of any zone, unless the guard is overridden.
+func (d *StackitDNSProvider) checkDeletions(
+	ctx context.Context,
+	deletions []*endpoint.Endpoint,
+	zones []stackitdnsclient.Zone,
+) error {
+	exceeded, err := d.exceededDeletionLimits(ctx, deletions, zones)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, limitErr := range exceeded {
+		if d.deletionGuard.Override {
+			d.logger.Warn("deletion limit exceeded, applying the deletions due to the override", zap.Error(limitErr))
+
+			continue
+		}
+
+		d.logger.Error("deletion limit exceeded, refusing the changes", zap.Error(limitErr))
+		d.metrics.CollectDeletionGuardRejection(limitErr.zone)
+		errs = append(errs, limitErr)
+	}
+
+	return errors.Join(errs...)
+}
+
+// exceededDeletionLimits returns the deletion limits of the zones which the deletions exceed. The percentage of a
+// zone is relative to the record sets currently in the zone. The ownership records of the TXT registry of external-dns
+// are neither counted as deletions nor as record sets of the zone, so that a removed record counts once.
+func (d *StackitDNSProvider) exceededDeletionLimits(
+	ctx context.Context,
+	deletions []*endpoint.Endpoint,
+	zones []stackitdnsclient.Zone,
+) ([]*deletionLimitError, error) {
+	counts := make(map[string]int, len(zones))
+	for _, deletion := range deletions {
+		// deletions of protected record sets are skipped and deletions without a zone fail later on, so neither
+		// of them is counted, and neither are the ownership records deleted along with the records they own
+		if d.protection.protects(deletion) || isOwnershipRecord(deletion.RecordType, deletion.Targets) {
+			continue
+		}
+
+		if zone, found := findBestMatchingZone(deletion.DNSName, zones); found {
+			counts[zone.Id]++
+		}
+	}
+
+	var exceeded []*deletionLimitError
+	for i := range zones {
+		zone := &zones[i]
+
+		count := counts[zone.Id]
+		limit := d.deletionGuard.limit(zone.DnsName)
+		if count == 0 || !limit.enabled() {
+			continue
+		}
+
+		rrSets, err := d.rrSetFetcherClient.fetchRecords(ctx, zone.Id, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		managed := 0
+		for j := range rrSets {
+			if !isOwnershipRecord(string(rrSets[j].Type), recordContents(rrSets[j].Records)) {
+				managed++
+			}
+		}
+
+		if limitErr := checkDeletionLimit(zone.DnsName, count, managed, limit); limitErr != nil {
+			exceeded = append(exceeded, limitErr)
+		}
+	}
+
+	return exceeded, nil
+}
+
+// checkDeletionLimit returns a deletionLimitError if deleting count of the rrSets record sets of a zone exceeds the
+// limit.
+func checkDeletionLimit(zoneName string, count, rrSets int, limit DeletionLimit) *deletionLimitError {
+	if limit.Count > 0 && count > limit.Count {
+		return &deletionLimitError{zone: zoneName, deletions: count, rrSets: rrSets, limit: limit}
+	}
+
+	if limit.Percent > 0 && rrSets > 0 && float64(count)*100/float64(rrSets) > limit.Percent {
+		return &deletionLimitError{zone: zoneName, deletions: count, rrSets: rrSets, limit: limit}
+	}
+
+	return nil
+}
+
+// isOwnershipRecord returns whether a record set is an ownership record of the TXT registry of external-dns, i.e. a
+// TXT record set with a heritage=external-dns record.
+func isOwnershipRecord(recordType string, contents []string) bool {
+	if recordType != endpoint.RecordTypeTXT {
+		return false
+	}
+
+	for _, content := range contents {
+		if strings.Contains(content, "heritage=external-dns") {
+			return true
+		}
+	}
+
+	return false
+}
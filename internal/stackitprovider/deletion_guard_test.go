@@ -0,0 +1,117 @@
+package stackitprovider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestApplyChangesDeletionGuard(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		guard         DeletionGuard
+		deletions     int
+		wantErr       string
+		wantRejection bool
+	}{
+		{
+			name:      "Disabled",
+			deletions: 10,
+		},
+		{
+			name:      "Below count",
+			guard:     DeletionGuard{Default: DeletionLimit{Count: 5}},
+			deletions: 5,
+		},
+		{
+			name:          "Count exceeded",
+			guard:         DeletionGuard{Default: DeletionLimit{Count: 5}},
+			deletions:     6,
+			wantErr:       `refusing to delete 6 of 10 record sets in zone "example.com", the deletion limit is 5 record sets`,
+			wantRejection: true,
+		},
+		{
+			name:          "Percentage exceeded",
+			guard:         DeletionGuard{Default: DeletionLimit{Count: 50, Percent: 25}},
+			deletions:     3,
+			wantErr:       `refusing to delete 3 of 10 record sets in zone "example.com", the deletion limit is 50 record sets and 25% of the record sets`,
+			wantRejection: true,
+		},
+		{
+			name: "Zone limit",
+			guard: DeletionGuard{
+				Default: DeletionLimit{Count: 1},
+				Zones:   map[string]DeletionLimit{"Example.com.": {Percent: 50}},
+			},
+			deletions: 5,
+		},
+		{
+			name:      "Override",
+			guard:     DeletionGuard{Default: DeletionLimit{Count: 1}, Override: true},
+			deletions: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			for i := 0; i < 10; i++ {
+				_, err := fakeServer.AddRecordSet(zone.Id, fmt.Sprintf("www%d.example.com.", i), "A", 300, "1.1.1.1")
+				assert.NoError(t, err)
+				// ownership records are not counted
+				_, err = fakeServer.AddRecordSet(zone.Id, fmt.Sprintf("a-www%d.example.com.", i), "TXT", 300, `"heritage=external-dns,external-dns/owner=default"`)
+				assert.NoError(t, err)
+			}
+
+			providerMetrics := mockmetrics.NewMockProviderMetrics(ctrl)
+			providerMetrics.EXPECT().CollectAPICall(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			providerMetrics.EXPECT().CollectChange(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			providerMetrics.EXPECT().SetLastSuccessfulSync(gomock.Any(), gomock.Any()).AnyTimes()
+			if tt.wantRejection {
+				providerMetrics.EXPECT().CollectDeletionGuardRejection("example.com").Times(1)
+			}
+
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+				config.Workers = 2
+				config.DeletionGuard = tt.guard
+				config.Metrics = providerMetrics
+			})
+
+			changes := &plan.Changes{}
+			for i := 0; i < tt.deletions; i++ {
+				changes.Delete = append(changes.Delete,
+					endpoint.NewEndpoint(fmt.Sprintf("www%d.example.com", i), "A", "1.1.1.1"),
+					endpoint.NewEndpoint(fmt.Sprintf("a-www%d.example.com", i), "TXT", `"heritage=external-dns,external-dns/owner=default"`),
+				)
+			}
+
+			err := stackitDnsProvider.ApplyChanges(context.Background(), changes)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Len(t, fakeServer.RecordSets(zone.Id), 20-2*tt.deletions)
+
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Len(t, fakeServer.RecordSets(zone.Id), 20)
+			assert.Zero(t, fakeServer.Requests(fake.OperationDeleteRecordSet))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package stackitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit"
+)
+
+// noMatchingZoneError is returned if a record set name belongs to none of the available zones.
+type noMatchingZoneError struct {
+	name       string
+	candidates []string
+}
+
+// newNoMatchingZoneError creates a noMatchingZoneError listing the dns names of the given zones as candidates.
+func newNoMatchingZoneError(name string, zones []stackitdnsclient.Zone) *noMatchingZoneError {
+	candidates := make([]string, 0, len(zones))
+	for i := range zones {
+		candidates = append(candidates, zones[i].DnsName)
+	}
+
+	return &noMatchingZoneError{
+		name:       name,
+		candidates: candidates,
+	}
+}
+
+func (e *noMatchingZoneError) Error() string {
+	return fmt.Sprintf(
+		"no matching zone found for %q, candidate zones: [%s]",
+		e.name,
+		strings.Join(e.candidates, ", "),
+	)
+}
+
+// isAmbiguousError returns whether an error leaves it open if the API applied the request. This is the case for
+// server errors and for requests which failed without any response, except if they were canceled by the caller or no
+// access token could be obtained.
+func isAmbiguousError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *oapierror.GenericOpenAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	// the request was not sent without an access token
+	var authErr *stackit.AuthError
+
+	return !errors.As(err, &authErr)
+}
+
+// isNotFoundError returns whether the API answered with 404 Not Found.
+func isNotFoundError(err error) bool {
+	var apiErr *oapierror.GenericOpenAPIError
+
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// quotaExceededErrorCode is the error code identifying an exceeded record quota in the error field of the error. The
+// error codes of the API are not documented, so isQuotaExceededError falls back to the message of the error.
+const quotaExceededErrorCode = "QuotaExceeded"
+
+// isAbortingError returns whether an error affects all further changes, so that the partial failure mode aborts like
+// the fail-fast mode. This is the case if no access token could be obtained, the API refused the authentication or
+// the permission, the rate limit is still exceeded after all retries or a record quota is exceeded.
+func isAbortingError(err error) bool {
+	var authErr *stackit.AuthError
+	if errors.As(err, &authErr) {
+		return true
+	}
+
+	var apiErr *oapierror.GenericOpenAPIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return true
+	default:
+		return isQuotaExceededError(apiErr)
+	}
+}
+
+// isQuotaExceededError returns whether the API refused a change since it exceeds the record quota of a zone. This is
+// the case if the error carries the quota error code, or its message says that a quota was exceeded. A message which
+// merely mentions a quota, e.g. of a validation error, does not count.
+func isQuotaExceededError(apiErr *oapierror.GenericOpenAPIError) bool {
+	var body stackitdnsclient.ErrorMessage
+	if err := json.Unmarshal(apiErr.Body, &body); err != nil {
+		return false
+	}
+
+	if body.GetError() == quotaExceededErrorCode {
+		return true
+	}
+
+	message := strings.ToLower(body.GetMessage())
+
+	return strings.Contains(message, "quota") && strings.Contains(message, "exceeded")
+}
+
+// deletionLimitError is returned if a change set deletes more record sets of a zone than its deletion limit allows.
+type deletionLimitError struct {
+	zone      string
+	deletions int
+	rrSets    int
+	limit     DeletionLimit
+}
+
+func (e *deletionLimitError) Error() string {
+	var limits []string
+	if e.limit.Count > 0 {
+		limits = append(limits, fmt.Sprintf("%d record sets", e.limit.Count))
+	}
+	if e.limit.Percent > 0 {
+		limits = append(limits, fmt.Sprintf("%g%% of the record sets", e.limit.Percent))
+	}
+
+	return fmt.Sprintf(
+		"refusing to delete %d of %d record sets in zone %q, the deletion limit is %s",
+		e.deletions,
+		e.rrSets,
+		e.zone,
+		strings.Join(limits, " and "),
+	)
+}
+
+// ConflictError is returned for a change of a record set which was changed by someone else since the state the
+// change is based on was read. Only the conflicting change is skipped, all other changes are applied.
+type ConflictError struct {
+	// Name is the name of the record set.
+	Name string
+	// RecordType is the type of the record set.
+	RecordType string
+	// Reason describes how the record set differs from the state the change is based on.
+	Reason string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("record set %q of type %s changed since it was read: %s", e.Name, e.RecordType, e.Reason)
+}
+
+// errRRSetDeleted is returned by verifyRRSet for a delete of a record set which was already deleted out of band.
+var errRRSetDeleted = errors.New("record set already deleted")
+
+// errDependentChange is the reason of a change skipped because an earlier change of the same record failed.
+var errDependentChange = errors.New("skipped, an earlier change of the same record failed")
+
+// ChangeError is the failure of a single change of a record set.
+type ChangeError struct {
+	// Action is the action of the change, CREATE, UPDATE or DELETE.
+	Action string
+	// Name is the name of the record set.
+	Name string
+	// RecordType is the type of the record set.
+	RecordType string
+	// Err is the reason of the failure.
+	Err error
+}
+
+// newChangeError creates a ChangeError for the change of the given task.
+func newChangeError(task changeTask, err error) *ChangeError {
+	return &ChangeError{
+		Action:     task.action,
+		Name:       task.change.DNSName,
+		RecordType: task.change.RecordType,
+		Err:        err,
+	}
+}
+
+func (e *ChangeError) Error() string {
+	return fmt.Sprintf("%s of record %q of type %s: %v", strings.ToLower(e.Action), e.Name, e.RecordType, e.Err)
+}
+
+func (e *ChangeError) Unwrap() error {
+	return e.Err
+}
+
+// PartialFailureError is returned if single changes of a change set failed while all other changes were applied.
+type PartialFailureError struct {
+	// Failures are the failed changes in execution order.
+	Failures []*ChangeError
+}
+
+func (e *PartialFailureError) Error() string {
+	lines := make([]string, 0, len(e.Failures)+1)
+	lines = append(lines, fmt.Sprintf("%d of the changes failed:", len(e.Failures)))
+	for _, failure := range e.Failures {
+		lines = append(lines, "- "+failure.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (e *PartialFailureError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure)
+	}
+
+	return errs
+}
@@ -0,0 +1,120 @@
+package stackitprovider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/zonefile"
+)
+
+// ExportZones returns the managed zones with the given DNS names and all of their active record sets, or all managed
+// zones if no names are given. The records are ordered by name and type.
+func (d *StackitDNSProvider) ExportZones(ctx context.Context, dnsNames []string) ([]zonefile.Zone, error) {
+	zones, err := d.zoneFetcherClient.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err = selectZones(zones, dnsNames)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]zonefile.Zone, 0, len(zones))
+	for i := range zones {
+		rrSets, err := d.rrSetFetcherClient.fetchRecords(ctx, zones[i].Id, nil)
+		if err != nil {
+			return nil, fmt.Errorf("fetching record sets of zone %q: %w", zones[i].DnsName, err)
+		}
+
+		result = append(result, zoneFromRRSets(&zones[i], rrSets))
+	}
+
+	return result, nil
+}
+
+// selectZones returns the zones with the given DNS names in the given order, or all zones if no names are given.
+func selectZones(zones []stackitdnsclient.Zone, dnsNames []string) ([]stackitdnsclient.Zone, error) {
+	if len(dnsNames) == 0 {
+		return zones, nil
+	}
+
+	result := make([]stackitdnsclient.Zone, 0, len(dnsNames))
+	for _, dnsName := range dnsNames {
+		i := slices.IndexFunc(zones, func(zone stackitdnsclient.Zone) bool {
+			return normalizeDNSName(zone.DnsName) == normalizeDNSName(dnsName)
+		})
+		if i < 0 {
+			return nil, newNoMatchingZoneError(dnsName, zones)
+		}
+
+		result = append(result, zones[i])
+	}
+
+	return result, nil
+}
+
+// zoneFromRRSets converts a zone and its record sets to a zone file.
+func zoneFromRRSets(zone *stackitdnsclient.Zone, rrSets []stackitdnsclient.RecordSet) zonefile.Zone {
+	result := zonefile.Zone{
+		Origin:  appendDotIfNotExists(zone.DnsName),
+		TTL:     safeInt32ToUint32(zone.DefaultTTL),
+		Records: make([]zonefile.Record, 0, countRecords(rrSets)),
+	}
+
+	for i := range rrSets {
+		rrSet := &rrSets[i]
+		for _, record := range rrSet.Records {
+			data := record.Content
+			if rrSet.Type == txtRecord {
+				data = zonefile.QuoteTXT(txtContentStrings(data)...)
+			}
+
+			result.Records = append(result.Records, zonefile.Record{
+				Name:    appendDotIfNotExists(rrSet.Name),
+				TTL:     safeInt32ToUint32(rrSet.Ttl),
+				Type:    string(rrSet.Type),
+				Data:    data,
+				Comment: rrSet.GetComment(),
+			})
+		}
+	}
+
+	slices.SortStableFunc(result.Records, func(a, b zonefile.Record) int {
+		if c := strings.Compare(normalizeDNSName(a.Name), normalizeDNSName(b.Name)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.Type, b.Type)
+	})
+
+	return result
+}
+
+// txtContentStrings returns the texts of the content of a TXT record. STACKIT stores quoted content, e.g. the chunks
+// of formatTXTContent, but content created without quotes is a single text.
+func txtContentStrings(content string) []string {
+	if !strings.HasPrefix(content, `"`) {
+		return []string{content}
+	}
+
+	texts, err := zonefile.SplitTXT(content)
+	if err != nil {
+		return []string{content}
+	}
+
+	return texts
+}
+
+// safeInt32ToUint32 converts a TTL of the API to uint32, clamping negative values to 0.
+func safeInt32ToUint32(v int32) uint32 {
+	if v < 0 {
+		return 0
+	}
+
+	return uint32(v)
+}
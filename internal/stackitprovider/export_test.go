@@ -0,0 +1,47 @@
+package stackitprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/zonefile"
+)
+
+func TestExportZones(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	fakeServer.AddZone("1234", "example.org")
+	_, err := fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.1.1.1", "2.2.2.2")
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "example.com.", "TXT", 60, `"heritage=external-dns" "second"`)
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "plain.example.com.", "TXT", 60, `say "hi"`)
+	assert.NoError(t, err)
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, nil)
+
+	zones, err := stackitDnsProvider.ExportZones(context.Background(), []string{"Example.com."})
+	assert.NoError(t, err)
+	assert.Equal(t, []zonefile.Zone{{
+		Origin: "example.com.",
+		TTL:    3600,
+		Records: []zonefile.Record{
+			{Name: "example.com.", TTL: 60, Type: "TXT", Data: `"heritage=external-dns" "second"`},
+			{Name: "plain.example.com.", TTL: 60, Type: "TXT", Data: `"say \"hi\""`},
+			{Name: "www.example.com.", TTL: 300, Type: "A", Data: "1.1.1.1"},
+			{Name: "www.example.com.", TTL: 300, Type: "A", Data: "2.2.2.2"},
+		},
+	}}, zones)
+
+	zones, err = stackitDnsProvider.ExportZones(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, zones, 2)
+
+	_, err = stackitDnsProvider.ExportZones(context.Background(), []string{"example.net"})
+	assert.EqualError(t, err, `no matching zone found for "example.net", candidate zones: [example.com, example.org]`)
+}
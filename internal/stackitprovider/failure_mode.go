@@ -0,0 +1,65 @@
+package stackitprovider
+
+import (
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// FailureMode decides how a change set continues once a change failed.
+type FailureMode string
+
+const (
+	// FailureModeFailFast cancels the change set on the first failed change.
+	FailureModeFailFast FailureMode = "fail-fast"
+	// FailureModePartial applies all changes which do not depend on a failed change and reports the failed changes
+	// in a single PartialFailureError. Failures affecting all changes, e.g. an exceeded quota or a refused
+	// authentication, still cancel the change set.
+	FailureModePartial FailureMode = "partial"
+)
+
+// FailureModes are all supported failure modes.
+var FailureModes = []FailureMode{FailureModeFailFast, FailureModePartial}
+
+// dependencyKey returns the record a change belongs to. Changes of the same record depend on each other, e.g. a
+// record on its ownership record. The TXT registry of external-dns names the ownership record after the record,
+// prefixed with the lowercase record type, e.g. a-www.example.com., so the prefix is removed from TXT names.
+// TXT records of the old registry format share the name of the record and need no special handling.
+//
+// The registry settings of external-dns are unknown to the webhook, so ownership records renamed with --txt-prefix
+// or --txt-suffix are not linked to their record. TXT records which just start with a record type and a hyphen,
+// e.g. a-backup.example.com., are linked to the record without the prefix, which only holds them back needlessly.
+func dependencyKey(dnsName, recordType string) string {
+	name := normalizeDNSName(dnsName)
+	if recordType != endpoint.RecordTypeTXT {
+		return name
+	}
+
+	prefix, owned, found := strings.Cut(name, "-")
+	if found && provider.SupportedRecordType(strings.ToUpper(prefix)) {
+		return owned
+	}
+
+	return name
+}
+
+// skipDependent splits off the tasks of records with a failed change. The skipped tasks are returned as failures.
+func skipDependent(tasks []changeTask, failed map[string]bool) ([]changeTask, []*ChangeError) {
+	if len(failed) == 0 {
+		return tasks, nil
+	}
+
+	remaining := make([]changeTask, 0, len(tasks))
+	var skipped []*ChangeError
+	for _, task := range tasks {
+		if failed[dependencyKey(task.change.DNSName, task.change.RecordType)] {
+			skipped = append(skipped, newChangeError(task, errDependentChange))
+
+			continue
+		}
+		remaining = append(remaining, task)
+	}
+
+	return remaining, skipped
+}
@@ -0,0 +1,239 @@
+package stackitprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestDependencyKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		dnsName    string
+		recordType string
+		want       string
+	}{
+		{"Record", "WWW.example.com", "A", "www.example.com."},
+		{"Ownership record", "a-www.example.com.", "TXT", "www.example.com."},
+		{"Ownership record of CNAME", "cname-www.example.com", "TXT", "www.example.com."},
+		{"Ownership record of the old format", "www.example.com", "TXT", "www.example.com."},
+		{"TXT record with hyphen", "my-www.example.com", "TXT", "my-www.example.com."},
+		{"Record with type prefix", "a-www.example.com", "A", "a-www.example.com."},
+		{"Ownership record with custom prefix", "owner.www.example.com", "TXT", "owner.www.example.com."},
+		{"TXT record starting with a record type", "a-backup.example.com", "TXT", "backup.example.com."},
+		{"TXT record starting with an unsupported record type", "mx-backup.example.com", "TXT", "mx-backup.example.com."},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, dependencyKey(tt.dnsName, tt.recordType))
+		})
+	}
+}
+
+func TestApplyChangesFailureMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		mode        FailureMode
+		wantPartial bool
+	}{
+		{"Fail fast", FailureModeFailFast, false},
+		{"Partial", FailureModePartial, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			_, err := fakeServer.AddRecordSet(zone.Id, "old.example.com.", "A", 300, "1.1.1.1")
+			assert.NoError(t, err)
+
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+				config.Workers = 2
+				config.FailureMode = tt.mode
+			})
+
+			err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+				Create: []*endpoint.Endpoint{
+					endpoint.NewEndpoint("a-new.example.com", "TXT", `"heritage=external-dns"`),
+					endpoint.NewEndpoint("new.example.com", "A", "2.2.2.2"),
+					endpoint.NewEndpoint("a-www.example.org", "TXT", `"heritage=external-dns"`),
+					endpoint.NewEndpoint("www.example.org", "A", "3.3.3.3"),
+				},
+				UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("missing.example.com", "A", "4.4.4.4")},
+				Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("old.example.com", "A", "1.1.1.1")},
+			})
+			assert.Error(t, err)
+
+			var names []string
+			for _, rrSet := range fakeServer.RecordSets(zone.Id) {
+				names = append(names, rrSet.Name)
+			}
+
+			var partialErr *PartialFailureError
+			if !tt.wantPartial {
+				// the failed update cancels all creates
+				assert.False(t, errors.As(err, &partialErr))
+				assert.EqualError(t, err, "record not found on record sets")
+				assert.Empty(t, names)
+
+				return
+			}
+
+			assert.ErrorAs(t, err, &partialErr)
+			assert.Len(t, partialErr.Failures, 3)
+			assert.Equal(t, "missing.example.com.", partialErr.Failures[0].Name)
+			assert.Equal(t, "a-www.example.org", partialErr.Failures[1].Name)
+			assert.Equal(t, "www.example.org", partialErr.Failures[2].Name)
+			assert.ErrorIs(t, partialErr.Failures[2], errDependentChange)
+			assert.EqualError(t, err, `3 of the changes failed:
+- update of record "missing.example.com." of type A: record not found on record sets
+- create of record "a-www.example.org" of type TXT: no matching zone found for "a-www.example.org", candidate zones: [example.com]
+- create of record "www.example.org" of type A: skipped, an earlier change of the same record failed`)
+			assert.ElementsMatch(t, []string{"a-new.example.com.", "new.example.com."}, names)
+		})
+	}
+}
+
+func TestApplyChangesPartialFailureAborts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fault   fake.Fault
+		wantErr string
+	}{
+		{"Quota", fake.QuotaFault(fake.OperationCreateRecordSet), fake.QuotaExceededMessage},
+		{
+			"Quota message",
+			fake.Fault{Operation: fake.OperationCreateRecordSet, StatusCode: http.StatusBadRequest, Message: "Record quota exceeded"},
+			"Record quota exceeded",
+		},
+		{
+			"Unauthorized",
+			fake.Fault{Operation: fake.OperationCreateRecordSet, StatusCode: http.StatusUnauthorized, Message: "unauthorized"},
+			"status code 401",
+		},
+		{"Rate limit", fake.RateLimitFault(fake.OperationCreateRecordSet), "status code 429"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			fakeServer.InjectFault(tt.fault)
+
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.FailureMode = FailureModePartial })
+
+			err := stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+				Create: []*endpoint.Endpoint{
+					endpoint.NewEndpoint("a-www.example.com", "TXT", `"heritage=external-dns"`),
+					endpoint.NewEndpoint("api.example.com", "A", "1.1.1.1"),
+				},
+			})
+			assert.ErrorContains(t, err, tt.wantErr)
+
+			var partialErr *PartialFailureError
+			assert.False(t, errors.As(err, &partialErr))
+			assert.Empty(t, fakeServer.RecordSets(zone.Id))
+			assert.Equal(t, 1, fakeServer.Requests(fake.OperationCreateRecordSet))
+		})
+	}
+}
+
+func TestApplyChangesPartialFailureAbortsOnAuthError(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+
+	// lets the first request of the changes pass and fails all further ones like a failed token refresh
+	var requests atomic.Int32
+	authFlow := stackitconfig.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodPost && requests.Add(1) > 1 {
+				return nil, &stackit.AuthError{Err: errors.New("get new access token: key expired")}
+			}
+
+			return next.RoundTrip(req)
+		})
+	})
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.FailureMode = FailureModePartial }, authFlow)
+
+	err := stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("www.example.com", "A", "1.1.1.1"),
+			endpoint.NewEndpoint("api.example.com", "A", "2.2.2.2"),
+			endpoint.NewEndpoint("mail.example.com", "A", "3.3.3.3"),
+		},
+	})
+	assert.ErrorContains(t, err, "authentication failed: get new access token: key expired")
+
+	var partialErr *PartialFailureError
+	assert.False(t, errors.As(err, &partialErr))
+	assert.Len(t, fakeServer.RecordSets(zone.Id), 1)
+}
+
+func TestApplyChangesPartialFailureContinuesOnValidationError(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	// the message mentions the quota, but does not say that it was exceeded
+	fakeServer.InjectFault(fake.Fault{
+		Operation:  fake.OperationCreateRecordSet,
+		StatusCode: http.StatusBadRequest,
+		Message:    "ttl exceeds the quota of the zone",
+		Times:      1,
+	})
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.FailureMode = FailureModePartial })
+
+	err := stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("www.example.com", "A", "1.1.1.1"),
+			endpoint.NewEndpoint("api.example.com", "A", "2.2.2.2"),
+		},
+	})
+
+	var partialErr *PartialFailureError
+	assert.ErrorAs(t, err, &partialErr)
+	assert.Len(t, partialErr.Failures, 1)
+	assert.Len(t, fakeServer.RecordSets(zone.Id), 1)
+}
+
+func TestNewStackitDNSProviderFailureMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{ProjectIds: []string{"1234"}, FailureMode: "best-effort"},
+		stackitconfig.WithToken("token"),
+	)
+	assert.EqualError(t, err, `unsupported failure mode "best-effort", supported modes: [fail-fast partial]`)
+}
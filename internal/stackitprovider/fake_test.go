@@ -0,0 +1,78 @@
+package stackitprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestApplyChangesFakeServer(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	_, err := fakeServer.AddRecordSet(zone.Id, "old.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.Workers = 2 })
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create:    []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("api.example.com", "A", 60, "2.2.2.2", "3.3.3.3")},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.1.1.1")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "4.4.4.4")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("old.example.com", "A", 300, "1.1.1.1")},
+	})
+	assert.NoError(t, err)
+
+	endpoints, err := stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("api.example.com", "A", 60, "2.2.2.2", "3.3.3.3"),
+		endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "4.4.4.4"),
+	}, endpoints)
+}
+
+func TestApplyChangesKeepsPortalComment(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, nil)
+
+	// the comment is set by hand, e.g. in the portal
+	err := stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.1.1.1").
+				WithProviderSpecific(commentProperty, "maintained by team-a"),
+		},
+	})
+	assert.NoError(t, err)
+
+	// an update without the comment property keeps the comment
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2")},
+	})
+	assert.NoError(t, err)
+
+	rrSets := fakeServer.RecordSets(zone.Id)
+	assert.Len(t, rrSets, 1)
+	assert.Equal(t, "2.2.2.2", rrSets[0].Records[0].Content)
+	assert.Equal(t, new("maintained by team-a"), rrSets[0].Comment)
+
+	// an empty comment property removes the comment
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "2.2.2.2").WithProviderSpecific(commentProperty, ""),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, fakeServer.RecordSets(zone.Id)[0].Comment)
+}
@@ -9,9 +9,10 @@ import (
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
-// findBestMatchingZone finds the best matching zone for a given record set name. The criteria are
-// that the zone name is contained in the record set name and that the zone name is the longest
-// possible match. Eg foo.bar.com. would have precedence over bar.com. if rr set name is foo.bar.com.
+// findBestMatchingZone finds the best matching zone for a given record set name. A zone matches if the
+// record set name equals the zone name or ends with "." + zone name, compared case-insensitively and
+// regardless of trailing dots. The longest matching zone wins. Eg foo.bar.com. would have precedence
+// over bar.com. if rr set name is foo.bar.com., while foo.notbar.com. matches neither of them.
 func findBestMatchingZone(
 	rrSetName string,
 	zones []stackitdnsclient.Zone,
@@ -19,9 +20,11 @@ func findBestMatchingZone(
 	count := 0
 	var domainZone *stackitdnsclient.Zone
 
+	name := normalizeDNSName(rrSetName)
 	for i := range zones {
 		zone := &zones[i]
-		if l := len(zone.DnsName); l > count && strings.Contains(rrSetName, zone.DnsName) {
+		zoneName := normalizeDNSName(zone.DnsName)
+		if l := len(zoneName); l > count && isInZone(name, zoneName) {
 			count = l
 			domainZone = zone
 		}
@@ -34,14 +37,26 @@ func findBestMatchingZone(
 	return domainZone, true
 }
 
-// findRRSet finds a record set by name and type in a list of record sets.
+// isInZone reports whether the normalized name is the normalized zone name itself or one of its subdomains.
+func isInZone(name, zoneName string) bool {
+	return name == zoneName || strings.HasSuffix(name, "."+zoneName)
+}
+
+// normalizeDNSName returns the lowercase FQDN form of a DNS name.
+func normalizeDNSName(name string) string {
+	return strings.ToLower(appendDotIfNotExists(name))
+}
+
+// findRRSet finds a record set by name and type in a list of record sets. Names are compared case-insensitively
+// and regardless of a trailing dot.
 func findRRSet(
 	rrSetName, recordType string,
 	rrSets []stackitdnsclient.RecordSet,
 ) (*stackitdnsclient.RecordSet, bool) {
+	name := normalizeDNSName(rrSetName)
 	for i := range rrSets {
 		rrSet := &rrSets[i]
-		if rrSet.Name == rrSetName && string(rrSet.Type) == recordType {
+		if normalizeDNSName(rrSet.Name) == name && string(rrSet.Type) == recordType {
 			return rrSet, true
 		}
 	}
@@ -63,10 +78,17 @@ func modifyChange(change *endpoint.Endpoint) {
 	change.DNSName = appendDotIfNotExists(change.DNSName)
 
 	if change.RecordTTL == 0 {
-		change.RecordTTL = 300
+		change.RecordTTL = defaultTTL
 	}
 }
 
+// getComment returns the record set comment requested by the provider-specific property of a change.
+func getComment(change *endpoint.Endpoint) string {
+	comment, _ := change.GetProviderSpecificProperty(commentProperty)
+
+	return comment
+}
+
 // getStackitRecordSetPayload returns a stackitdnsclient.RecordSetPayload from a change for the api client.
 func getStackitRecordSetPayload(change *endpoint.Endpoint) stackitdnsclient.CreateRecordSetPayload {
 	records := make([]stackitdnsclient.RecordPayload, len(change.Targets))
@@ -82,12 +104,18 @@ func getStackitRecordSetPayload(change *endpoint.Endpoint) stackitdnsclient.Crea
 		}
 	}
 
-	return stackitdnsclient.CreateRecordSetPayload{
+	payload := stackitdnsclient.CreateRecordSetPayload{
 		Name:    change.DNSName,
 		Records: records,
 		Ttl:     safeTTLToInt32(change.RecordTTL),
 		Type:    stackitdnsclient.CreateRecordSetPayloadType(change.RecordType),
 	}
+
+	if comment := getComment(change); comment != "" {
+		payload.Comment = &comment
+	}
+
+	return payload
 }
 
 // getStackitPartialUpdateRecordSetPayload returns a stackitdnsclient.PartialUpdateRecordSetPayload from a change for the api client.
@@ -105,11 +133,47 @@ func getStackitPartialUpdateRecordSetPayload(change *endpoint.Endpoint) stackitd
 		}
 	}
 
-	return stackitdnsclient.PartialUpdateRecordSetPayload{
+	payload := stackitdnsclient.PartialUpdateRecordSetPayload{
 		Name:    &change.DNSName,
 		Records: records,
 		Ttl:     safeTTLToInt32(change.RecordTTL),
 	}
+
+	// the comment is only sent if the property is set, so that a comment set in the portal survives the update. An
+	// empty property removes the comment.
+	if comment, ok := change.GetProviderSpecificProperty(commentProperty); ok {
+		payload.Comment = &comment
+	}
+
+	return payload
+}
+
+// applyPartialUpdate returns a copy of the record set with the changes of the partial update payload applied.
+func applyPartialUpdate(
+	rrSet stackitdnsclient.RecordSet,
+	payload stackitdnsclient.PartialUpdateRecordSetPayload,
+) stackitdnsclient.RecordSet {
+	if payload.Name != nil {
+		rrSet.Name = *payload.Name
+	}
+
+	if payload.Ttl != nil {
+		rrSet.Ttl = *payload.Ttl
+	}
+
+	if payload.Comment != nil {
+		rrSet.Comment = payload.Comment
+	}
+
+	if payload.Records != nil {
+		records := make([]stackitdnsclient.Record, len(payload.Records))
+		for i := range payload.Records {
+			records[i] = stackitdnsclient.Record{Content: payload.Records[i].Content}
+		}
+		rrSet.Records = records
+	}
+
+	return rrSet
 }
 
 // getLogFields returns a log.Fields object for a change.
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -98,6 +99,7 @@ func TestFindBestMatchingZone(t *testing.T) {
 		{DnsName: "foo.com"},
 		{DnsName: "bar.com"},
 		{DnsName: "baz.com"},
+		{DnsName: "sub.foo.com."},
 	}
 
 	tests := []struct {
@@ -108,6 +110,11 @@ func TestFindBestMatchingZone(t *testing.T) {
 	}{
 		{"Matching Zone", "www.foo.com", &zones[0], true},
 		{"No Matching Zone", "www.test.com", nil, false},
+		{"Zone Apex", "foo.com.", &zones[0], true},
+		{"Case Insensitive", "WWW.Foo.COM.", &zones[0], true},
+		{"Longest Match", "www.sub.foo.com.", &zones[3], true},
+		{"No Label Boundary", "www.notfoo.com.", nil, false},
+		{"Zone In The Middle", "foo.com.example.org.", nil, false},
 	}
 
 	for _, tt := range tests {
@@ -140,6 +147,9 @@ func TestFindRRSet(t *testing.T) {
 	}{
 		{"Matching RRSet", "www.foo.com", "A", &rrSets[0], true},
 		{"No Matching RRSet", "www.test.com", "A", nil, false},
+		{"Case Insensitive", "WWW.Bar.COM", "A", &rrSets[1], true},
+		{"Trailing Dot", "www.baz.com.", "A", &rrSets[2], true},
+		{"Other Type", "www.foo.com", "AAAA", nil, false},
 	}
 
 	for _, tt := range tests {
@@ -216,6 +226,28 @@ func TestGetStackitRRSetRecordPatch(t *testing.T) {
 	}
 }
 
+func TestRecordSetPayloadComment(t *testing.T) {
+	t.Parallel()
+
+	change := &endpoint.Endpoint{
+		DNSName:    "test.",
+		RecordTTL:  endpoint.TTL(300),
+		RecordType: "A",
+		Targets:    endpoint.Targets{"192.0.2.1"},
+	}
+
+	assert.Nil(t, getStackitRecordSetPayload(change).Comment)
+
+	change.WithProviderSpecific(commentProperty, "owner: team-a")
+	assert.Equal(t, new("owner: team-a"), getStackitRecordSetPayload(change).Comment)
+
+	patch := getStackitPartialUpdateRecordSetPayload(change)
+	assert.Equal(t, new("owner: team-a"), patch.Comment)
+
+	rrSet := applyPartialUpdate(stackitdnsclient.RecordSet{Comment: new("old")}, patch)
+	assert.Equal(t, new("owner: team-a"), rrSet.Comment)
+}
+
 func TestFormatTXTContent(t *testing.T) {
 	t.Parallel()
 
@@ -312,3 +344,17 @@ func TestUnformatTXTContent(t *testing.T) {
 		})
 	}
 }
+
+func TestNoMatchingZoneError(t *testing.T) {
+	t.Parallel()
+
+	zones := []stackitdnsclient.Zone{
+		{DnsName: "foo.com."},
+		{DnsName: "bar.com."},
+	}
+
+	err := newNoMatchingZoneError("www.test.com.", zones)
+	if got, want := err.Error(), `no matching zone found for "www.test.com.", candidate zones: [foo.com., bar.com.]`; got != want {
+		t.Errorf("noMatchingZoneError.Error() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package stackitprovider
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/zonefile"
+)
+
+const (
+	soaRecord = "SOA"
+	nsRecord  = "NS"
+)
+
+// PlanZoneImport returns the changes which make the managed zone with the origin of the zone file match the file.
+// The changes are applied with ApplyChanges. Record sets missing in the file are only deleted if prune is set. The
+// SOA record and the NS records of the apex are managed by STACKIT and are neither imported nor deleted.
+func (d *StackitDNSProvider) PlanZoneImport(ctx context.Context, zone zonefile.Zone, prune bool) (*plan.Changes, error) {
+	zones, err := d.zoneFetcherClient.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := selectZones(zones, []string{zone.Origin})
+	if err != nil {
+		return nil, err
+	}
+	resultZone := &selected[0]
+
+	rrSets, err := d.rrSetFetcherClient.fetchRecords(ctx, resultZone.Id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	desired := d.importEndpoints(resultZone, zone)
+	current := currentEndpoints(resultZone, rrSets)
+
+	changes := &plan.Changes{}
+	desiredKeys := make(map[endpoint.EndpointKey]struct{}, len(desired))
+	for _, ep := range desired {
+		desiredKeys[ep.Key()] = struct{}{}
+
+		existing, found := current[ep.Key()]
+		if !found {
+			changes.Create = append(changes.Create, ep)
+
+			continue
+		}
+
+		if sameRecords(existing, ep) {
+			continue
+		}
+
+		// the record set is matched by its name as stored by STACKIT and keeps its comment
+		ep.DNSName = existing.DNSName
+		if comment := getComment(existing); comment != "" {
+			ep.WithProviderSpecific(commentProperty, comment)
+		}
+
+		changes.UpdateOld = append(changes.UpdateOld, existing)
+		changes.UpdateNew = append(changes.UpdateNew, ep)
+	}
+
+	if prune {
+		// the record sets are deleted in the order of the zone
+		for i := range rrSets {
+			key := rrSetKey(&rrSets[i])
+			ep, found := current[key]
+			if _, keep := desiredKeys[key]; !found || keep {
+				continue
+			}
+
+			changes.Delete = append(changes.Delete, ep)
+		}
+	}
+
+	return changes, nil
+}
+
+// importEndpoints converts the records of the zone file to endpoints in the canonical STACKIT form, merging records
+// with the same name and type. Records outside of the zone and records managed by STACKIT are skipped.
+func (d *StackitDNSProvider) importEndpoints(resultZone *stackitdnsclient.Zone, zone zonefile.Zone) []*endpoint.Endpoint {
+	var endpoints []*endpoint.Endpoint
+	endpointsByKey := make(map[endpoint.EndpointKey]*endpoint.Endpoint)
+
+	for _, record := range zone.Records {
+		if !isInZone(normalizeDNSName(record.Name), normalizeDNSName(resultZone.DnsName)) {
+			d.logger.Warn("skipping record outside of the zone", zap.String("name", record.Name), zap.String("zone", resultZone.DnsName))
+
+			continue
+		}
+
+		if isManagedByStackit(resultZone, record.Name, record.Type) {
+			d.logger.Info("skipping record managed by STACKIT", zap.String("name", record.Name), zap.String("type", record.Type))
+
+			continue
+		}
+
+		target, err := importTarget(record)
+		if err != nil {
+			d.logger.Warn("skipping record with invalid data", zap.String("name", record.Name), zap.String("type", record.Type), zap.Error(err))
+
+			continue
+		}
+
+		ep := endpoint.NewEndpointWithTTL(record.Name, record.Type, endpoint.TTL(record.TTL), target)
+		normalizeEndpoint(ep)
+
+		if existing, found := endpointsByKey[ep.Key()]; found {
+			existing.Targets = normalizeTargets(existing.RecordType, append(existing.Targets, ep.Targets...))
+
+			continue
+		}
+
+		endpointsByKey[ep.Key()] = ep
+		endpoints = append(endpoints, ep)
+	}
+
+	return endpoints
+}
+
+// currentEndpoints returns the endpoints of the record sets of the zone by the key of the desired endpoint they
+// correspond to, i.e. with lowercase FQDN. Record sets managed by STACKIT are left out.
+func currentEndpoints(resultZone *stackitdnsclient.Zone, rrSets []stackitdnsclient.RecordSet) map[endpoint.EndpointKey]*endpoint.Endpoint {
+	result := make(map[endpoint.EndpointKey]*endpoint.Endpoint, len(rrSets))
+
+	for i := range rrSets {
+		r := &rrSets[i]
+
+		name, recordType, ttl, records, ok := recordSetCoreFields(r)
+		if !ok || isManagedByStackit(resultZone, name, recordType) {
+			continue
+		}
+
+		ep := endpointFromRecords(name, recordType, ttl, records)
+		if comment := r.GetComment(); comment != "" {
+			ep.WithProviderSpecific(commentProperty, comment)
+		}
+
+		result[rrSetKey(r)] = ep
+	}
+
+	return result
+}
+
+// rrSetKey returns the key of the desired endpoint a record set corresponds to.
+func rrSetKey(rrSet *stackitdnsclient.RecordSet) endpoint.EndpointKey {
+	return endpoint.EndpointKey{DNSName: normalizeDNSName(rrSet.Name), RecordType: string(rrSet.Type)}
+}
+
+// isManagedByStackit returns whether a record set is maintained by STACKIT itself: the SOA record and the NS records
+// of the apex.
+func isManagedByStackit(zone *stackitdnsclient.Zone, name, recordType string) bool {
+	return recordType == soaRecord || (recordType == nsRecord && normalizeDNSName(name) == normalizeDNSName(zone.DnsName))
+}
+
+// importTarget returns the target of an endpoint for the data of a record. The character strings of TXT data are
+// joined into a single quoted string, the form in which external-dns handles TXT targets.
+func importTarget(record zonefile.Record) (string, error) {
+	if record.Type != txtRecord {
+		return record.Data, nil
+	}
+
+	texts, err := zonefile.SplitTXT(record.Data)
+	if err != nil {
+		return "", err
+	}
+
+	return `"` + txtEscaper.Replace(strings.Join(texts, "")) + `"`, nil
+}
+
+// txtEscaper escapes the characters which are not allowed unescaped within a quoted string.
+var txtEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// sameRecords returns whether an existing endpoint already has the TTL and targets of the desired one.
+func sameRecords(existing, desired *endpoint.Endpoint) bool {
+	if existing.RecordTTL != desired.RecordTTL {
+		return false
+	}
+
+	existingTargets := normalizeTargets(existing.RecordType, existing.Targets)
+
+	return existingTargets.Same(slices.Clone(desired.Targets))
+}
@@ -0,0 +1,74 @@
+package stackitprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/zonefile"
+)
+
+func TestPlanZoneImport(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	_, err := fakeServer.AddRecordSet(zone.Id, "example.com.", "NS", 3600, "ns1.stackit.cloud.")
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "same.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "changed.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "old.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.Workers = 2 })
+
+	file, err := zonefile.Parse(strings.NewReader(`$TTL 300
+@	SOA	ns1.provider.net. hostmaster 1 3600 600 1209600 60
+@	NS	ns1.provider.net.
+same	A	1.1.1.1
+changed	60	A	2.2.2.2
+new	A	3.3.3.3
+new	A	4.4.4.4
+txt	TXT	"v=spf1" " -all"
+other.org.	A	5.5.5.5
+`), "example.com")
+	assert.NoError(t, err)
+
+	changes, err := stackitDnsProvider.PlanZoneImport(context.Background(), file, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		newImportEndpoint("new.example.com", "A", 300, "3.3.3.3", "4.4.4.4"),
+		newImportEndpoint("txt.example.com", "TXT", 300, `"v=spf1 -all"`),
+	}, changes.Create)
+	assert.Equal(t, []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("changed.example.com.", "A", 300, "1.1.1.1")}, changes.UpdateOld)
+	assert.Equal(t, []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("changed.example.com.", "A", 60, "2.2.2.2")}, changes.UpdateNew)
+	assert.Empty(t, changes.Delete)
+
+	changes, err = stackitDnsProvider.PlanZoneImport(context.Background(), file, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("old.example.com.", "A", 300, "1.1.1.1")}, changes.Delete)
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), changes)
+	assert.NoError(t, err)
+
+	changes, err = stackitDnsProvider.PlanZoneImport(context.Background(), file, true)
+	assert.NoError(t, err)
+	assert.False(t, changes.HasChanges())
+
+	_, err = stackitDnsProvider.PlanZoneImport(context.Background(), zonefile.Zone{Origin: "example.net."}, false)
+	assert.EqualError(t, err, `no matching zone found for "example.net.", candidate zones: [example.com]`)
+}
+
+// newImportEndpoint returns an endpoint in the canonical STACKIT form.
+func newImportEndpoint(dnsName, recordType string, ttl endpoint.TTL, targets ...string) *endpoint.Endpoint {
+	ep := endpoint.NewEndpointWithTTL(dnsName, recordType, ttl, targets...)
+	normalizeEndpoint(ep)
+
+	return ep
+}
@@ -0,0 +1,28 @@
+package stackitprovider
+
+import (
+	"time"
+)
+
+const (
+	syncRecords      = "records"
+	syncApplyChanges = "apply_changes"
+)
+
+// noopMetrics is used if no metrics are configured.
+type noopMetrics struct{}
+
+func (noopMetrics) CollectAPICall(string, string, float64)    {}
+func (noopMetrics) CollectChange(string, string, string)      {}
+func (noopMetrics) SetZones(int)                              {}
+func (noopMetrics) SetRecords(string, int)                    {}
+func (noopMetrics) SetLastSuccessfulSync(string, float64)     {}
+func (noopMetrics) CollectDeletionGuardRejection(string)      {}
+func (noopMetrics) CollectProtectedRecordSkip(string, string) {}
+func (noopMetrics) CollectUpdateConflict(string, string)      {}
+func (noopMetrics) SetQuarantinedRecords(int)                 {}
+
+// collectSync sets the timestamp of the last successful sync of the given operation to now.
+func (d *StackitDNSProvider) collectSync(operation string) {
+	d.metrics.SetLastSuccessfulSync(operation, float64(time.Now().Unix()))
+}
@@ -0,0 +1,100 @@
+package stackitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+)
+
+func TestRecordsMetrics(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/1234/zones", getProjectZonesHandler(t, stackitdnsclient.Zone{Id: "zone", DnsName: "example.com"}))
+	mux.HandleFunc("/v1/projects/1234/zones/zone/rrsets", getProjectRRSetsHandler(t, "www.example.com.", "1.2.3.4"))
+
+	ctrl := gomock.NewController(t)
+	providerMetrics := mockmetrics.NewMockProviderMetrics(ctrl)
+	providerMetrics.EXPECT().SetRecords("example.com", 1)
+	providerMetrics.EXPECT().SetZones(1)
+	providerMetrics.EXPECT().SetLastSuccessfulSync(syncRecords, gomock.Any())
+
+	stackitDnsProvider, err := getMetricsTestProvider(server, providerMetrics)
+	assert.NoError(t, err)
+
+	_, err = stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestRecordsMetricsFailure(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/1234/zones", responseHandler(nil, http.StatusInternalServerError))
+
+	// no metrics are expected if the records cannot be listed
+	ctrl := gomock.NewController(t)
+	stackitDnsProvider, err := getMetricsTestProvider(server, mockmetrics.NewMockProviderMetrics(ctrl))
+	assert.NoError(t, err)
+
+	_, err = stackitDnsProvider.Records(context.Background())
+	assert.Error(t, err)
+}
+
+func TestApplyChangesMetrics(t *testing.T) {
+	t.Parallel()
+
+	createResponse, err := json.Marshal(getValidRecordSetResponse())
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/1234/zones", getProjectZonesHandler(t, stackitdnsclient.Zone{Id: "zone", DnsName: "example.com"}))
+	mux.HandleFunc("/v1/projects/1234/zones/zone/rrsets", responseHandler(createResponse, http.StatusAccepted))
+
+	ctrl := gomock.NewController(t)
+	providerMetrics := mockmetrics.NewMockProviderMetrics(ctrl)
+	providerMetrics.EXPECT().CollectChange(CREATE, "A", "example.com")
+	providerMetrics.EXPECT().SetLastSuccessfulSync(syncApplyChanges, gomock.Any())
+
+	stackitDnsProvider, err := getMetricsTestProvider(server, providerMetrics)
+	assert.NoError(t, err)
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{{DNSName: "www.example.com", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}}},
+	})
+	assert.NoError(t, err)
+}
+
+func getMetricsTestProvider(server *httptest.Server, providerMetrics *mockmetrics.MockProviderMetrics) (*StackitDNSProvider, error) {
+	return NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{
+			ProjectIds: []string{"1234"},
+			Workers:    1,
+			Metrics:    providerMetrics,
+		},
+		stackitconfig.WithHTTPClient(server.Client()),
+		stackitconfig.WithEndpoint(server.URL),
+		stackitconfig.WithToken("token"))
+}
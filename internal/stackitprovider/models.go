@@ -17,6 +17,14 @@ type ErrorMessage struct {
 type changeTask struct {
 	change *endpoint.Endpoint
 	action string
+	// old is the state external-dns based an update on, if known.
+	old *endpoint.Endpoint
+}
+
+// changeResult is the outcome of a task returned by the worker.
+type changeResult struct {
+	task changeTask
+	err  error
 }
 
 // endpointError is a list of endpoints and an error to pass to workers.
@@ -0,0 +1,279 @@
+package stackitprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+// names of the API calls issued for the actions
+const (
+	operationCreate = "CreateRecordSet"
+	operationUpdate = "PartialUpdateRecordSet"
+	operationDelete = "DeleteRecordSet"
+)
+
+// PlanChanges returns the API calls which ApplyChanges would issue for the changes, in execution order, without
+// issuing them. Changes which would not be applied or rejected by the API, e.g. because no zone matches or a CNAME
+// record is placed at the zone apex, are part of the plan with their error. Like in ApplyChanges, the changes of a
+// record with a failed change in an earlier batch are planned as skipped, and a failure which cancels the change set
+// skips all later batches. A change set which the deletion guard would refuse is planned with the refusal as error of
+// the plan.
+func (d *StackitDNSProvider) PlanChanges(ctx context.Context, changes *plan.Changes) (result *report.ChangePlan, err error) {
+	ctx, span := d.tracer.Start(ctx, "stackitprovider.PlanChanges", trace.WithAttributes(
+		attributeChanges.Int(len(changes.Create)+len(changes.UpdateNew)+len(changes.Delete)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	zones, err := d.zoneFetcherClient.zones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &report.ChangePlan{Operations: []report.PlannedOperation{}}
+
+	exceeded, err := d.exceededDeletionLimits(ctx, changes.Delete, zones)
+	if err != nil {
+		return nil, err
+	}
+	if len(exceeded) > 0 && !d.deletionGuard.Override {
+		errs := make([]error, 0, len(exceeded))
+		for _, limitErr := range exceeded {
+			errs = append(errs, limitErr)
+		}
+		result.Error = errors.Join(errs...).Error()
+	}
+
+	failed := map[string]bool{}
+	var canceled error
+	for i, batch := range d.buildBatches(changes) {
+		var operations []report.PlannedOperation
+		if canceled != nil {
+			operations = cancelBatch(batch, canceled)
+		} else {
+			operations, canceled = d.planBatch(ctx, batch, zones, failed)
+		}
+
+		for j := range operations {
+			operations[j].Batch = i
+
+			if operations[j].Error != "" {
+				result.Errors++
+			}
+			if operations[j].Conflict != "" {
+				result.Conflicts++
+			}
+		}
+		result.Operations = append(result.Operations, operations...)
+	}
+
+	return result, nil
+}
+
+// planBatch plans the tasks of a batch. The changes of records in failed are skipped like in ApplyChanges, and the
+// records of the failed and quarantined changes of the batch are added to failed. It returns the failure which would
+// cancel the change set after the batch, if any.
+func (d *StackitDNSProvider) planBatch(
+	ctx context.Context,
+	batch []changeTask,
+	zones []stackitdnsclient.Zone,
+	failed map[string]bool,
+) ([]report.PlannedOperation, error) {
+	operations := make([]report.PlannedOperation, 0, len(batch))
+	var batchFailed []string
+	var canceled error
+	for _, task := range batch {
+		key := dependencyKey(task.change.DNSName, task.change.RecordType)
+		if failed[key] {
+			operation := newPlannedOperation(task)
+			operation.Error = errDependentChange.Error()
+			operations = append(operations, operation)
+
+			continue
+		}
+
+		// the changes depending on a quarantined change are held back like those of a failed change
+		if entry, quarantined := d.quarantine.quarantined(task); quarantined {
+			operation := newPlannedOperation(task)
+			operation.Skipped = fmt.Sprintf("quarantined after %d failures until %s", entry.failures, entry.retryAt.Format(time.RFC3339))
+			operations = append(operations, operation)
+			batchFailed = append(batchFailed, key)
+
+			continue
+		}
+
+		operation, err := d.planTask(ctx, task, zones)
+		operations = append(operations, operation)
+		if err == nil {
+			continue
+		}
+		batchFailed = append(batchFailed, key)
+
+		// conflicts never cancel the change set, other failures only in the fail-fast mode or if they affect all
+		// changes, see handleRRSetWithWorkers
+		var conflictErr *ConflictError
+		if canceled == nil && !errors.As(err, &conflictErr) && (d.failureMode != FailureModePartial || isAbortingError(err)) {
+			canceled = newChangeError(task, err)
+		}
+	}
+
+	for _, key := range batchFailed {
+		failed[key] = true
+	}
+
+	return operations, canceled
+}
+
+// cancelBatch plans the tasks of a batch which would not be applied since a failure canceled the change set.
+func cancelBatch(batch []changeTask, canceled error) []report.PlannedOperation {
+	operations := make([]report.PlannedOperation, 0, len(batch))
+	for _, task := range batch {
+		operation := newPlannedOperation(task)
+		operation.Skipped = fmt.Sprintf("change set canceled, %v", canceled)
+		operations = append(operations, operation)
+	}
+
+	return operations
+}
+
+// newPlannedOperation returns the operation of a task before it is resolved, named like the change worker names the
+// record set.
+func newPlannedOperation(task changeTask) report.PlannedOperation {
+	change := task.change.DeepCopy()
+	modifyChange(change)
+
+	return report.PlannedOperation{
+		Action: task.action,
+		Name:   change.DNSName,
+		Type:   change.RecordType,
+	}
+}
+
+// planTask resolves the zone, project and record set of a task like the change worker does. It returns the error the
+// change would fail with, which is also set as error or conflict of the operation.
+func (d *StackitDNSProvider) planTask(
+	ctx context.Context,
+	task changeTask,
+	zones []stackitdnsclient.Zone,
+) (report.PlannedOperation, error) {
+	// the change is modified like in the worker, without touching the requested change
+	change := task.change.DeepCopy()
+	modifyChange(change)
+
+	operation := newPlannedOperation(task)
+
+	if d.protection.protects(change) {
+		operation.Skipped = "protected record set"
+
+		return operation, nil
+	}
+
+	var resultZone *stackitdnsclient.Zone
+	var resultRRSet *stackitdnsclient.RecordSet
+	var cached bool
+	switch task.action {
+	case CREATE:
+		operation.Operation = operationCreate
+
+		var found bool
+		if resultZone, found = findBestMatchingZone(change.DNSName, zones); !found {
+			err := newNoMatchingZoneError(change.DNSName, zones)
+			operation.Error = err.Error()
+
+			return operation, err
+		}
+	case UPDATE, DELETE:
+		operation.Operation = operationUpdate
+		if task.action == DELETE {
+			operation.Operation = operationDelete
+		}
+
+		var err error
+		if resultZone, resultRRSet, cached, err = d.rrSetFetcherClient.getRRSetForUpdateDeletion(ctx, change, zones); err != nil {
+			operation.Error = err.Error()
+
+			return operation, err
+		}
+		operation.RecordSetId = resultRRSet.Id
+	}
+
+	operation.ZoneId = resultZone.Id
+	operation.Zone = resultZone.DnsName
+
+	projectId, err := d.projects.owner(resultZone.Id)
+	if err != nil {
+		operation.Error = err.Error()
+
+		return operation, err
+	}
+	operation.ProjectId = projectId
+
+	if resultRRSet != nil {
+		conflicts, err := d.findConflicts(ctx, task.action, task.old, resultZone, resultRRSet, cached)
+		if err != nil {
+			operation.Error = err.Error()
+
+			return operation, err
+		}
+
+		if conflicts.deleted {
+			operation.Skipped = errRRSetDeleted.Error()
+
+			return operation, nil
+		}
+
+		if conflict := conflicts.refused(d.conflictPolicy); conflict != nil {
+			operation.Conflict = conflict.Error()
+
+			return operation, conflict
+		}
+	}
+
+	if task.action == DELETE {
+		return operation, nil
+	}
+
+	payload := getStackitRecordSetPayload(change)
+	operation.TTL = int64(*payload.Ttl)
+	operation.Comment = getComment(change)
+	operation.Records = payloadContents(payload.Records)
+
+	if err := validateChange(change, resultZone); err != nil {
+		operation.Error = err.Error()
+
+		return operation, err
+	}
+
+	return operation, nil
+}
+
+// validateChange checks a change to be created or updated against the constraints known to be enforced by the API.
+func validateChange(change *endpoint.Endpoint, zone *stackitdnsclient.Zone) error {
+	if len(change.Targets) == 0 {
+		return errors.New("record set without records")
+	}
+
+	if change.RecordTTL < minTTL || change.RecordTTL > maxTTL {
+		return fmt.Errorf("ttl %d out of range [%d, %d]", change.RecordTTL, minTTL, maxTTL)
+	}
+
+	if change.RecordType == endpoint.RecordTypeCNAME {
+		if normalizeDNSName(change.DNSName) == normalizeDNSName(zone.DnsName) {
+			return errors.New("CNAME record at the zone apex")
+		}
+
+		if len(change.Targets) > 1 {
+			return errors.New("CNAME record with more than one target")
+		}
+	}
+
+	return nil
+}
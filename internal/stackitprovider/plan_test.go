@@ -0,0 +1,219 @@
+package stackitprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestPlanChanges(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	oldRRSet, err := fakeServer.AddRecordSet(zone.Id, "old.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+	oldTXT, err := fakeServer.AddRecordSet(zone.Id, "old.example.com.", "TXT", 300, `"heritage=external-dns"`)
+	assert.NoError(t, err)
+	wwwRRSet, err := fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+
+	// in the partial failure mode, none of the failures cancels the change set, so every change is resolved
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+		config.Workers = 2
+		config.FailureMode = FailureModePartial
+	})
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("api.example.com", "A", "2.2.2.2"),
+			endpoint.NewEndpoint("example.com", "CNAME", "other.example.org"),
+			endpoint.NewEndpoint("api.example.org", "A", "2.2.2.2"),
+		},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 300, "1.1.1.1")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.com", "A", 60, "4.4.4.4")},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("old.example.com", "A", "1.1.1.1"),
+			endpoint.NewEndpoint("old.example.com", "TXT", `"heritage=external-dns"`),
+			endpoint.NewEndpoint("missing.example.com", "A", "1.1.1.1"),
+		},
+	}
+
+	changePlan, err := stackitDnsProvider.PlanChanges(context.Background(), changes)
+	assert.NoError(t, err)
+	assert.Equal(t, &report.ChangePlan{
+		Operations: []report.PlannedOperation{
+			{Batch: 0, Action: DELETE, Operation: operationDelete, ProjectId: "1234", ZoneId: zone.Id, Zone: "example.com", RecordSetId: oldRRSet.Id, Name: "old.example.com.", Type: "A"},
+			{Batch: 0, Action: DELETE, Operation: operationDelete, Name: "missing.example.com.", Type: "A", Error: "record not found on record sets"},
+			{Batch: 1, Action: DELETE, Operation: operationDelete, ProjectId: "1234", ZoneId: zone.Id, Zone: "example.com", RecordSetId: oldTXT.Id, Name: "old.example.com.", Type: "TXT"},
+			{Batch: 2, Action: UPDATE, Operation: operationUpdate, ProjectId: "1234", ZoneId: zone.Id, Zone: "example.com", RecordSetId: wwwRRSet.Id, Name: "www.example.com.", Type: "A", TTL: 60, Records: []string{"4.4.4.4"}},
+			{Batch: 3, Action: CREATE, Operation: operationCreate, ProjectId: "1234", ZoneId: zone.Id, Zone: "example.com", Name: "api.example.com.", Type: "A", TTL: 300, Records: []string{"2.2.2.2"}},
+			{Batch: 3, Action: CREATE, Operation: operationCreate, ProjectId: "1234", ZoneId: zone.Id, Zone: "example.com", Name: "example.com.", Type: "CNAME", TTL: 300, Records: []string{"other.example.org"}, Error: "CNAME record at the zone apex"},
+			{Batch: 3, Action: CREATE, Operation: operationCreate, Name: "api.example.org.", Type: "A", Error: `no matching zone found for "api.example.org.", candidate zones: [example.com]`},
+		},
+		Errors: 3,
+	}, changePlan)
+
+	// the requested changes are not modified and nothing is applied
+	assert.Equal(t, "api.example.com", changes.Create[0].DNSName)
+	assert.Len(t, fakeServer.RecordSets(zone.Id), 3)
+	assert.Zero(t, fakeServer.Requests(fake.OperationCreateRecordSet))
+	assert.Zero(t, fakeServer.Requests(fake.OperationPartialUpdateRecordSet))
+	assert.Zero(t, fakeServer.Requests(fake.OperationDeleteRecordSet))
+}
+
+func TestPlanChangesFailures(t *testing.T) {
+	t.Parallel()
+
+	canceled := `change set canceled, delete of record "missing.example.com" of type A: record not found on record sets`
+
+	tests := []struct {
+		name       string
+		mode       FailureMode
+		wantErrors int
+		want       map[string]report.PlannedOperation
+	}{
+		{
+			"Fail fast",
+			FailureModeFailFast,
+			1,
+			map[string]report.PlannedOperation{
+				"missing.example.com.":   {Batch: 0, Error: "record not found on record sets"},
+				"other.example.com.":     {Batch: 0},
+				"a-missing.example.com.": {Batch: 1, Skipped: canceled},
+				"api.example.com.":       {Batch: 2, Skipped: canceled},
+			},
+		},
+		{
+			"Partial",
+			FailureModePartial,
+			2,
+			map[string]report.PlannedOperation{
+				"missing.example.com.":   {Batch: 0, Error: "record not found on record sets"},
+				"other.example.com.":     {Batch: 0},
+				"a-missing.example.com.": {Batch: 1, Error: errDependentChange.Error()},
+				"api.example.com.":       {Batch: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			_, err := fakeServer.AddRecordSet(zone.Id, "other.example.com.", "A", 300, "1.1.1.1")
+			assert.NoError(t, err)
+
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) { config.FailureMode = tt.mode })
+
+			// the ownership record of the missing record depends on it
+			changePlan, err := stackitDnsProvider.PlanChanges(context.Background(), &plan.Changes{
+				Create: []*endpoint.Endpoint{
+					endpoint.NewEndpoint("a-missing.example.com", "TXT", `"heritage=external-dns"`),
+					endpoint.NewEndpoint("api.example.com", "A", "2.2.2.2"),
+				},
+				Delete: []*endpoint.Endpoint{
+					endpoint.NewEndpoint("missing.example.com", "A", "1.1.1.1"),
+					endpoint.NewEndpoint("other.example.com", "A", "1.1.1.1"),
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantErrors, changePlan.Errors)
+
+			operations := map[string]report.PlannedOperation{}
+			for _, operation := range changePlan.Operations {
+				operations[operation.Name] = report.PlannedOperation{
+					Batch:   operation.Batch,
+					Error:   operation.Error,
+					Skipped: operation.Skipped,
+				}
+			}
+			assert.Equal(t, tt.want, operations)
+		})
+	}
+}
+
+func TestPlanChangesDeletionGuard(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		override bool
+		wantErr  string
+	}{
+		{"Refused", false, `refusing to delete 2 of 4 record sets in zone "example.com", the deletion limit is 1 record sets`},
+		{"Override", true, ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeServer := fake.NewServer()
+			zone := fakeServer.AddZone("1234", "example.com")
+			for _, name := range []string{"a.example.com.", "b.example.com.", "c.example.com.", "d.example.com."} {
+				_, err := fakeServer.AddRecordSet(zone.Id, name, "A", 300, "1.1.1.1")
+				assert.NoError(t, err)
+			}
+
+			stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+				config.DeletionGuard = DeletionGuard{Default: DeletionLimit{Count: 1}, Override: tt.override}
+			})
+
+			changePlan, err := stackitDnsProvider.PlanChanges(context.Background(), &plan.Changes{
+				Delete: []*endpoint.Endpoint{
+					endpoint.NewEndpoint("a.example.com", "A", "1.1.1.1"),
+					endpoint.NewEndpoint("b.example.com", "A", "1.1.1.1"),
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantErr, changePlan.Error)
+			assert.Len(t, changePlan.Operations, 2)
+			assert.Zero(t, fakeServer.Requests(fake.OperationDeleteRecordSet))
+		})
+	}
+}
+
+func TestValidateChange(t *testing.T) {
+	t.Parallel()
+
+	zone := &getValidZoneResponseAll().Zones[0]
+
+	tests := []struct {
+		name    string
+		change  *endpoint.Endpoint
+		wantErr string
+	}{
+		{"Valid", endpoint.NewEndpointWithTTL("www."+zone.DnsName, "A", 300, "1.1.1.1"), ""},
+		{"No targets", endpoint.NewEndpointWithTTL("www."+zone.DnsName, "A", 300), "record set without records"},
+		{"TTL out of range", endpoint.NewEndpointWithTTL("www."+zone.DnsName, "A", 30, "1.1.1.1"), "ttl 30 out of range [60, 99999999]"},
+		{"CNAME at apex", endpoint.NewEndpointWithTTL(zone.DnsName, "CNAME", 300, "other.org"), "CNAME record at the zone apex"},
+		{"CNAME with several targets", endpoint.NewEndpointWithTTL("www."+zone.DnsName, "CNAME", 300, "a.org", "b.org"), "CNAME record with more than one target"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateChange(tt.change, zone)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
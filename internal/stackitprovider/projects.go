@@ -0,0 +1,95 @@
+package stackitprovider
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// zoneProjects knows which STACKIT projects are managed and which of them owns a zone. The zone fetcher
+// registers the owner of every zone it fetches, so that record set requests can be routed to its project.
+type zoneProjects struct {
+	// projectIds are the managed projects in the order they were configured.
+	projectIds []string
+	// domains restricts the zones of a project to the given domains. Projects without entry are not restricted.
+	domains map[string][]string
+
+	mu     sync.RWMutex
+	owners map[string]string
+}
+
+// newZoneProjects creates the projects from the list of project ids and the mapping of domains to projects.
+// Projects only named in the mapping are added to the managed projects.
+func newZoneProjects(projectIds []string, domainProjects map[string]string) *zoneProjects {
+	p := &zoneProjects{
+		domains: map[string][]string{},
+		owners:  map[string]string{},
+	}
+
+	for _, projectId := range projectIds {
+		p.addProject(projectId)
+	}
+
+	// sort the domains for a deterministic order of the projects
+	domains := make([]string, 0, len(domainProjects))
+	for domain := range domainProjects {
+		domains = append(domains, domain)
+	}
+	slices.Sort(domains)
+
+	for _, domain := range domains {
+		projectId := domainProjects[domain]
+		p.addProject(projectId)
+		p.domains[projectId] = append(p.domains[projectId], normalizeDNSName(domain))
+	}
+
+	return p
+}
+
+func (p *zoneProjects) addProject(projectId string) {
+	if projectId != "" && !slices.Contains(p.projectIds, projectId) {
+		p.projectIds = append(p.projectIds, projectId)
+	}
+}
+
+// allowsZone returns whether the project may own the zone with the given DNS name.
+func (p *zoneProjects) allowsZone(projectId, dnsName string) bool {
+	domains, ok := p.domains[projectId]
+	if !ok {
+		return true
+	}
+
+	dnsName = normalizeDNSName(dnsName)
+	for _, domain := range domains {
+		if isInZone(dnsName, domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setOwner registers the project owning the zone.
+func (p *zoneProjects) setOwner(zoneId, projectId string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.owners[zoneId] = projectId
+}
+
+// owner returns the project owning the zone. With a single managed project it is the owner of every zone.
+func (p *zoneProjects) owner(zoneId string) (string, error) {
+	if len(p.projectIds) == 1 {
+		return p.projectIds[0], nil
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	projectId, ok := p.owners[zoneId]
+	if !ok {
+		return "", fmt.Errorf("no project found for zone %q", zoneId)
+	}
+
+	return projectId, nil
+}
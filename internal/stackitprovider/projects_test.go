@@ -0,0 +1,147 @@
+package stackitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestNewZoneProjects(t *testing.T) {
+	t.Parallel()
+
+	projects := newZoneProjects([]string{"prod", "", "prod"}, map[string]string{
+		"staging.example.com": "staging",
+		"example.org":         "prod",
+	})
+
+	assert.Equal(t, []string{"prod", "staging"}, projects.projectIds)
+	assert.True(t, projects.allowsZone("prod", "example.org"))
+	assert.True(t, projects.allowsZone("prod", "sub.example.org."))
+	assert.False(t, projects.allowsZone("prod", "example.com"))
+	assert.True(t, projects.allowsZone("staging", "staging.example.com"))
+	assert.False(t, projects.allowsZone("staging", "example.com"))
+	assert.True(t, projects.allowsZone("unrestricted", "example.com"))
+}
+
+func TestZoneProjectsOwner(t *testing.T) {
+	t.Parallel()
+
+	single := newZoneProjects([]string{"prod"}, nil)
+	projectId, err := single.owner("unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod", projectId)
+
+	multiple := newZoneProjects([]string{"prod", "staging"}, nil)
+	multiple.setOwner("zone", "staging")
+
+	projectId, err = multiple.owner("zone")
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", projectId)
+
+	_, err = multiple.owner("unknown")
+	assert.Error(t, err)
+}
+
+func TestNoProjects(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStackitDNSProvider(zap.NewNop(), &Config{}, stackitconfig.WithToken("token"))
+	assert.Error(t, err)
+}
+
+func TestMultipleProjects(t *testing.T) {
+	t.Parallel()
+
+	var stagingCreates atomic.Int32
+
+	createResponse, err := json.Marshal(getValidRecordSetResponse())
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/prod/zones", getProjectZonesHandler(t, stackitdnsclient.Zone{Id: "prod-zone", DnsName: "example.com"}))
+	mux.HandleFunc("/v1/projects/staging/zones", getProjectZonesHandler(t,
+		stackitdnsclient.Zone{Id: "staging-zone", DnsName: "staging.example.com"},
+		// not mapped to the staging project and therefore ignored
+		stackitdnsclient.Zone{Id: "other-zone", DnsName: "example.net"},
+	))
+	mux.HandleFunc("/v1/projects/prod/zones/prod-zone/rrsets", getProjectRRSetsHandler(t, "www.example.com.", "1.2.3.4"))
+	mux.HandleFunc("/v1/projects/staging/zones/staging-zone/rrsets", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			stagingCreates.Add(1)
+			responseHandler(createResponse, http.StatusAccepted)(w, r)
+
+			return
+		}
+
+		getProjectRRSetsHandler(t, "www.staging.example.com.", "5.6.7.8")(w, r)
+	})
+
+	stackitDnsProvider, err := NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{
+			ProjectIds:     []string{"prod"},
+			DomainProjects: map[string]string{"staging.example.com": "staging"},
+			Workers:        1,
+		},
+		stackitconfig.WithHTTPClient(server.Client()),
+		stackitconfig.WithEndpoint(server.URL),
+		// we need a non-empty token for the bootstrapping not to fail
+		stackitconfig.WithToken("token"),
+	)
+	assert.NoError(t, err)
+
+	endpoints, err := stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "new.staging.example.com", RecordType: "A", Targets: endpoint.Targets{"9.9.9.9"}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), stagingCreates.Load())
+}
+
+func getProjectZonesHandler(t *testing.T, zones ...stackitdnsclient.Zone) http.HandlerFunc {
+	t.Helper()
+
+	response, err := json.Marshal(stackitdnsclient.ListZonesResponse{
+		ItemsPerPage: int32(len(zones)),
+		TotalItems:   int32(len(zones)),
+		TotalPages:   1,
+		Zones:        zones,
+	})
+	assert.NoError(t, err)
+
+	return responseHandler(response, http.StatusOK)
+}
+
+func getProjectRRSetsHandler(t *testing.T, name, content string) http.HandlerFunc {
+	t.Helper()
+
+	response, err := json.Marshal(stackitdnsclient.ListRecordSetsResponse{
+		ItemsPerPage: 1,
+		TotalItems:   1,
+		TotalPages:   1,
+		RrSets: []stackitdnsclient.RecordSet{
+			{Name: name, Type: "A", Ttl: 300, Records: []stackitdnsclient.Record{{Content: content}}},
+		},
+	})
+	assert.NoError(t, err)
+
+	return responseHandler(response, http.StatusOK)
+}
@@ -0,0 +1,112 @@
+package stackitprovider
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ProtectedRecords are record sets which are never created, updated or deleted, e.g. records maintained by hand
+// next to external-dns.
+type ProtectedRecords struct {
+	// Names are the protected record set names. A name is matched exactly, as glob pattern if it contains one of
+	// the characters *?[ or as regular expression if it is enclosed in slashes, e.g. /^api-[0-9]+\.example\.com$/.
+	// Names are compared case-insensitively and without trailing dot.
+	Names []string
+	// Types are the protected record types, e.g. MX. All record sets of these types are protected.
+	Types []string
+}
+
+// recordProtection matches changes against the protected records. A nil *recordProtection protects nothing.
+type recordProtection struct {
+	names    []string
+	patterns []string
+	regexps  []*regexp.Regexp
+	types    []string
+}
+
+// newRecordProtection compiles the protected records.
+func newRecordProtection(protected ProtectedRecords) (*recordProtection, error) {
+	p := &recordProtection{}
+	for _, name := range protected.Names {
+		switch {
+		case len(name) > 2 && strings.HasPrefix(name, "/") && strings.HasSuffix(name, "/"):
+			re, err := regexp.Compile("(?i)" + name[1:len(name)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid protected name %q: %w", name, err)
+			}
+			p.regexps = append(p.regexps, re)
+		case strings.ContainsAny(name, "*?["):
+			pattern := protectedName(name)
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid protected name %q: %w", name, err)
+			}
+			p.patterns = append(p.patterns, pattern)
+		default:
+			p.names = append(p.names, protectedName(name))
+		}
+	}
+
+	for _, recordType := range protected.Types {
+		p.types = append(p.types, strings.ToUpper(recordType))
+	}
+
+	return p, nil
+}
+
+// protects returns whether the record set of the change is protected.
+func (p *recordProtection) protects(change *endpoint.Endpoint) bool {
+	if p == nil {
+		return false
+	}
+
+	if slices.Contains(p.types, strings.ToUpper(change.RecordType)) {
+		return true
+	}
+
+	name := protectedName(change.DNSName)
+	if slices.Contains(p.names, name) {
+		return true
+	}
+
+	for _, pattern := range p.patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	for _, re := range p.regexps {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// protectedName returns the form of a name which is compared with the protected names.
+func protectedName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// skipProtected returns whether the change touches a protected record set. Skipped changes are logged and counted.
+func (d *StackitDNSProvider) skipProtected(action string, change *endpoint.Endpoint) bool {
+	if !d.protection.protects(change) {
+		return false
+	}
+
+	d.logger.Warn(
+		"skipping change of protected record set",
+		zap.String("record", change.DNSName),
+		zap.String("type", change.RecordType),
+		zap.String("action", action),
+	)
+	d.metrics.CollectProtectedRecordSkip(action, change.RecordType)
+
+	return true
+}
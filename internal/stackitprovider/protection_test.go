@@ -0,0 +1,111 @@
+package stackitprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestRecordProtection(t *testing.T) {
+	t.Parallel()
+
+	protection, err := newRecordProtection(ProtectedRecords{
+		Names: []string{"Apex.example.com.", "*.static.example.com", `/^api-[0-9]+\.example\.com$/`},
+		Types: []string{"mx"},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		change     *endpoint.Endpoint
+		protection *recordProtection
+		want       bool
+	}{
+		{"Exact name", endpoint.NewEndpoint("apex.example.com.", "A", "1.1.1.1"), protection, true},
+		{"Exact name without trailing dot", endpoint.NewEndpoint("APEX.example.com", "TXT", "text"), protection, true},
+		{"Glob pattern", endpoint.NewEndpoint("www.static.example.com", "A", "1.1.1.1"), protection, true},
+		{"Glob pattern parent", endpoint.NewEndpoint("static.example.com", "A", "1.1.1.1"), protection, false},
+		{"Regular expression", endpoint.NewEndpoint("api-42.example.com.", "A", "1.1.1.1"), protection, true},
+		{"Regular expression mismatch", endpoint.NewEndpoint("api-x.example.com.", "A", "1.1.1.1"), protection, false},
+		{"Record type", endpoint.NewEndpoint("example.com", "MX", "10 mail.example.com"), protection, true},
+		{"Unprotected", endpoint.NewEndpoint("www.example.com", "A", "1.1.1.1"), protection, false},
+		{"No protection", endpoint.NewEndpoint("apex.example.com", "A", "1.1.1.1"), nil, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.protection.protects(tt.change))
+		})
+	}
+}
+
+func TestNewRecordProtectionErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := newRecordProtection(ProtectedRecords{Names: []string{"/api-(/"}})
+	assert.ErrorContains(t, err, `invalid protected name "/api-(/"`)
+
+	_, err = newRecordProtection(ProtectedRecords{Names: []string{"[a.example.com"}})
+	assert.ErrorContains(t, err, `invalid protected name "[a.example.com"`)
+}
+
+func TestApplyChangesProtectedRecords(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	_, err := fakeServer.AddRecordSet(zone.Id, "manual.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "old.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+
+	providerMetrics := mockmetrics.NewMockProviderMetrics(ctrl)
+	providerMetrics.EXPECT().CollectAPICall(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	providerMetrics.EXPECT().CollectChange(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	providerMetrics.EXPECT().SetLastSuccessfulSync(gomock.Any(), gomock.Any()).AnyTimes()
+	providerMetrics.EXPECT().CollectProtectedRecordSkip(DELETE, "A").Times(1)
+	providerMetrics.EXPECT().CollectProtectedRecordSkip(CREATE, "MX").Times(1)
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+		config.Workers = 2
+		config.ProtectedRecords = ProtectedRecords{Names: []string{"manual.example.com"}, Types: []string{"MX"}}
+		config.Metrics = providerMetrics
+	})
+
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("example.com", "MX", "10 mail.example.com"),
+			endpoint.NewEndpoint("new.example.com", "A", "2.2.2.2"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("manual.example.com", "A", "1.1.1.1"),
+			endpoint.NewEndpoint("old.example.com", "A", "1.1.1.1"),
+		},
+	}
+
+	changePlan, err := stackitDnsProvider.PlanChanges(context.Background(), changes)
+	assert.NoError(t, err)
+	assert.Equal(t, "protected record set", changePlan.Operations[0].Skipped)
+	assert.Empty(t, changePlan.Operations[1].Skipped)
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), changes)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, rrSet := range fakeServer.RecordSets(zone.Id) {
+		names = append(names, rrSet.Name)
+	}
+	assert.ElementsMatch(t, []string{"manual.example.com.", "new.example.com."}, names)
+}
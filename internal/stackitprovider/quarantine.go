@@ -0,0 +1,308 @@
+package stackitprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+// QuarantinePolicy quarantines record sets whose changes failed repeatedly, e.g. because the API rejects the
+// records, so that their changes are not issued again with every sync.
+type QuarantinePolicy struct {
+	// Threshold is the number of consecutive failures after which a record set is quarantined. The quarantine is
+	// disabled if it is 0.
+	Threshold int
+	// Backoff is the time after which the change of a quarantined record set is retried. It doubles with every
+	// further failure.
+	Backoff time.Duration
+	// MaxBackoff limits the backoff. The backoff is not limited if it is 0.
+	MaxBackoff time.Duration
+}
+
+// validate returns an error if the policy is enabled with an invalid backoff.
+func (p QuarantinePolicy) validate() error {
+	if p.Threshold < 0 {
+		return fmt.Errorf("quarantine threshold must not be negative, got %d", p.Threshold)
+	}
+
+	if p.Threshold > 0 && (p.Backoff <= 0 || p.MaxBackoff < 0) {
+		return fmt.Errorf("quarantine backoff must be positive, got %s with a maximum of %s", p.Backoff, p.MaxBackoff)
+	}
+
+	return nil
+}
+
+// quarantineKey identifies the record set whose failures are tracked.
+type quarantineKey struct {
+	name       string
+	recordType string
+}
+
+// newQuarantineKey returns the key of the record set of a change.
+func newQuarantineKey(change *endpoint.Endpoint) quarantineKey {
+	return quarantineKey{
+		name:       normalizeDNSName(change.DNSName),
+		recordType: change.RecordType,
+	}
+}
+
+// quarantineEntry are the consecutive failures of the change of a record set.
+type quarantineEntry struct {
+	key    quarantineKey
+	action string
+	// desired is the state the failed change requested, see desiredState.
+	desired   string
+	failures  int
+	lastError string
+	retryAt   time.Time
+}
+
+// failureQuarantine tracks the consecutive failures of the changes per record set. A nil *failureQuarantine tracks
+// nothing. It is safe for concurrent use.
+type failureQuarantine struct {
+	mu      sync.Mutex
+	policy  QuarantinePolicy
+	entries map[quarantineKey]*quarantineEntry
+	now     func() time.Time
+}
+
+// newFailureQuarantine returns the quarantine of the policy, or nil if the policy is disabled.
+func newFailureQuarantine(policy QuarantinePolicy) *failureQuarantine {
+	if policy.Threshold == 0 {
+		return nil
+	}
+
+	return &failureQuarantine{
+		policy:  policy,
+		entries: map[quarantineKey]*quarantineEntry{},
+		now:     time.Now,
+	}
+}
+
+// desiredState returns the state a change requests for its record set. The failures of a record set are reset once
+// the requested state changes.
+func desiredState(action string, change *endpoint.Endpoint) string {
+	if action == DELETE {
+		return action
+	}
+
+	ttl := change.RecordTTL
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+
+	targets := normalizeTargets(change.RecordType, change.Targets)
+	slices.Sort(targets)
+
+	return fmt.Sprintf("%s %d %q %q", action, ttl, []string(targets), getComment(change))
+}
+
+// retain clears the failures of all record sets which the changes do not touch anymore or whose requested state
+// changed. external-dns plans the failed changes again with every sync until the desired endpoint changes.
+func (q *failureQuarantine) retain(changes *plan.Changes) {
+	if q == nil {
+		return
+	}
+
+	desired := map[quarantineKey]string{}
+	for action, endpoints := range map[string][]*endpoint.Endpoint{
+		CREATE: changes.Create,
+		UPDATE: changes.UpdateNew,
+		DELETE: changes.Delete,
+	} {
+		for _, change := range endpoints {
+			desired[newQuarantineKey(change)] = desiredState(action, change)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for key, entry := range q.entries {
+		if state, ok := desired[key]; !ok || state != entry.desired {
+			delete(q.entries, key)
+		}
+	}
+}
+
+// quarantined returns the entry of the record set of the task if it is quarantined and its backoff has not elapsed.
+func (q *failureQuarantine) quarantined(task changeTask) (quarantineEntry, bool) {
+	if q == nil {
+		return quarantineEntry{}, false
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.entries[newQuarantineKey(task.change)]
+	if !ok || entry.failures < q.policy.Threshold || !q.now().Before(entry.retryAt) {
+		return quarantineEntry{}, false
+	}
+
+	if entry.desired != desiredState(task.action, task.change) {
+		return quarantineEntry{}, false
+	}
+
+	return *entry, true
+}
+
+// record tracks the result of the change of a task. A success clears the failures of the record set. Failures
+// which are not caused by the change itself, like conflicts, canceled changes or an exceeded quota, are ignored.
+// It returns whether the record set was quarantined.
+func (q *failureQuarantine) record(task changeTask, err error) bool {
+	if q == nil {
+		return false
+	}
+
+	key := newQuarantineKey(task.change)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err == nil {
+		delete(q.entries, key)
+
+		return false
+	}
+
+	var conflictErr *ConflictError
+	if errors.Is(err, context.Canceled) || errors.Is(err, errDependentChange) || errors.As(err, &conflictErr) ||
+		isAbortingError(err) {
+		return false
+	}
+
+	desired := desiredState(task.action, task.change)
+	entry, ok := q.entries[key]
+	if !ok || entry.desired != desired {
+		entry = &quarantineEntry{key: key, action: task.action, desired: desired}
+		q.entries[key] = entry
+	}
+
+	entry.failures++
+	entry.lastError = strings.TrimSpace(err.Error())
+	if entry.failures < q.policy.Threshold {
+		return false
+	}
+
+	entry.retryAt = q.now().Add(q.backoff(entry.failures - q.policy.Threshold))
+
+	return true
+}
+
+// backoff returns the backoff after the given number of failures beyond the threshold.
+func (q *failureQuarantine) backoff(failures int) time.Duration {
+	backoff := q.policy.Backoff
+	for i := 0; i < failures; i++ {
+		if q.policy.MaxBackoff > 0 && backoff >= q.policy.MaxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+
+	if q.policy.MaxBackoff > 0 && backoff > q.policy.MaxBackoff {
+		return q.policy.MaxBackoff
+	}
+
+	return backoff
+}
+
+// records returns the quarantined record sets sorted by name and type.
+func (q *failureQuarantine) records() []report.QuarantinedRecord {
+	records := []report.QuarantinedRecord{}
+	if q == nil {
+		return records
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, entry := range q.entries {
+		if entry.failures < q.policy.Threshold {
+			continue
+		}
+
+		records = append(records, report.QuarantinedRecord{
+			Name:      entry.key.name,
+			Type:      entry.key.recordType,
+			Action:    entry.action,
+			Failures:  entry.failures,
+			LastError: entry.lastError,
+			RetryAt:   entry.retryAt,
+		})
+	}
+
+	slices.SortFunc(records, func(a, b report.QuarantinedRecord) int {
+		return strings.Compare(a.Name+" "+a.Type, b.Name+" "+b.Type)
+	})
+
+	return records
+}
+
+// QuarantinedRecords returns the record sets whose changes are quarantined after failing repeatedly.
+func (d *StackitDNSProvider) QuarantinedRecords() []report.QuarantinedRecord {
+	return d.quarantine.records()
+}
+
+// skipQuarantined splits off the changes of quarantined record sets from the tasks. Skipped changes are logged and
+// returned, so that the changes depending on them can be held back.
+func (d *StackitDNSProvider) skipQuarantined(tasks []changeTask) (remaining, skipped []changeTask) {
+	if d.quarantine == nil {
+		return tasks, nil
+	}
+
+	remaining = make([]changeTask, 0, len(tasks))
+	for _, task := range tasks {
+		entry, quarantined := d.quarantine.quarantined(task)
+		if !quarantined {
+			remaining = append(remaining, task)
+
+			continue
+		}
+		skipped = append(skipped, task)
+
+		d.logger.Warn(
+			"skipping change of quarantined record set",
+			zap.String("record", task.change.DNSName),
+			zap.String("type", task.change.RecordType),
+			zap.String("action", task.action),
+			zap.Int("failures", entry.failures),
+			zap.Time("retryAt", entry.retryAt),
+		)
+	}
+
+	return remaining, skipped
+}
+
+// recordQuarantine tracks the result of a change in the quarantine.
+func (d *StackitDNSProvider) recordQuarantine(task changeTask, err error) {
+	if !d.quarantine.record(task, err) {
+		return
+	}
+
+	d.logger.Error(
+		"change failed repeatedly, quarantining record set",
+		zap.String("record", task.change.DNSName),
+		zap.String("type", task.change.RecordType),
+		zap.String("action", task.action),
+		zap.Error(err),
+	)
+}
+
+// collectQuarantine sets the number of quarantined record sets.
+func (d *StackitDNSProvider) collectQuarantine() {
+	if d.quarantine == nil {
+		return
+	}
+
+	d.metrics.SetQuarantinedRecords(len(d.quarantine.records()))
+}
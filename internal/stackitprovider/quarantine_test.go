@@ -0,0 +1,178 @@
+package stackitprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
+)
+
+func TestFailureQuarantine(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	quarantine := newFailureQuarantine(QuarantinePolicy{Threshold: 2, Backoff: time.Minute, MaxBackoff: 3 * time.Minute})
+	quarantine.now = func() time.Time { return now }
+
+	task := changeTask{action: CREATE, change: endpoint.NewEndpoint("www.example.com", "CNAME", "example.com")}
+	failure := errors.New("invalid record")
+
+	assert.False(t, quarantine.record(task, failure))
+	_, quarantined := quarantine.quarantined(task)
+	assert.False(t, quarantined)
+
+	// ignored failures are not counted
+	assert.False(t, quarantine.record(task, &ConflictError{Name: "www.example.com", RecordType: "CNAME"}))
+	assert.False(t, quarantine.record(task, context.Canceled))
+
+	assert.True(t, quarantine.record(task, failure))
+	entry, quarantined := quarantine.quarantined(task)
+	assert.True(t, quarantined)
+	assert.Equal(t, 2, entry.failures)
+	assert.Equal(t, now.Add(time.Minute), entry.retryAt)
+
+	// the backoff doubles up to the maximum
+	now = now.Add(time.Minute)
+	_, quarantined = quarantine.quarantined(task)
+	assert.False(t, quarantined)
+	assert.True(t, quarantine.record(task, failure))
+	assert.Equal(t, now.Add(2*time.Minute), quarantine.records()[0].RetryAt)
+	assert.True(t, quarantine.record(task, failure))
+	assert.Equal(t, now.Add(3*time.Minute), quarantine.records()[0].RetryAt)
+	assert.Equal(t, 4, quarantine.records()[0].Failures)
+
+	// a changed desired endpoint is not quarantined and clears the failures
+	changed := changeTask{action: CREATE, change: endpoint.NewEndpoint("www.example.com", "CNAME", "other.example.com")}
+	_, quarantined = quarantine.quarantined(changed)
+	assert.False(t, quarantined)
+	quarantine.retain(&plan.Changes{Create: []*endpoint.Endpoint{task.change}})
+	assert.Len(t, quarantine.records(), 1)
+	quarantine.retain(&plan.Changes{Create: []*endpoint.Endpoint{changed.change}})
+	assert.Empty(t, quarantine.records())
+
+	// a success clears the failures
+	assert.False(t, quarantine.record(task, failure))
+	assert.False(t, quarantine.record(task, nil))
+	assert.False(t, quarantine.record(task, failure))
+	assert.Empty(t, quarantine.records())
+}
+
+func TestApplyChangesQuarantine(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	fakeServer := fake.NewServer()
+	fakeServer.AddZone("1234", "example.com")
+	fakeServer.InjectFault(fake.Fault{
+		Operation:  fake.OperationCreateRecordSet,
+		StatusCode: http.StatusBadRequest,
+		Message:    "invalid record",
+	})
+
+	providerMetrics := mockmetrics.NewMockProviderMetrics(ctrl)
+	providerMetrics.EXPECT().CollectAPICall(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	providerMetrics.EXPECT().SetLastSuccessfulSync(gomock.Any(), gomock.Any()).AnyTimes()
+	providerMetrics.EXPECT().SetQuarantinedRecords(1).Times(2)
+	providerMetrics.EXPECT().SetQuarantinedRecords(0).AnyTimes()
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+		config.Quarantine = QuarantinePolicy{Threshold: 2, Backoff: time.Hour}
+		config.Metrics = providerMetrics
+	})
+
+	changes := &plan.Changes{Create: []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.com", "CNAME", "example.com")}}
+
+	// the record set is quarantined after the second failure
+	for i := 0; i < 2; i++ {
+		err := stackitDnsProvider.ApplyChanges(context.Background(), changes)
+		assert.ErrorContains(t, err, "invalid record")
+	}
+
+	records := stackitDnsProvider.QuarantinedRecords()
+	assert.Len(t, records, 1)
+	assert.Equal(t, "www.example.com.", records[0].Name)
+	assert.Equal(t, "CNAME", records[0].Type)
+	assert.Equal(t, 2, records[0].Failures)
+
+	changePlan, err := stackitDnsProvider.PlanChanges(context.Background(), changes)
+	assert.NoError(t, err)
+	assert.Contains(t, changePlan.Operations[0].Skipped, "quarantined after 2 failures")
+
+	// the quarantined change is not issued
+	err = stackitDnsProvider.ApplyChanges(context.Background(), changes)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fakeServer.Requests(fake.OperationCreateRecordSet))
+
+	// a changed desired endpoint clears the quarantine
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.com", "CNAME", "other.example.com")},
+	})
+	assert.ErrorContains(t, err, "invalid record")
+	assert.Equal(t, 3, fakeServer.Requests(fake.OperationCreateRecordSet))
+	assert.Empty(t, stackitDnsProvider.QuarantinedRecords())
+}
+
+func TestNewStackitDNSProviderQuarantine(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{ProjectIds: []string{"1234"}, Quarantine: QuarantinePolicy{Threshold: 3}},
+		stackitconfig.WithToken("token"),
+	)
+	assert.EqualError(t, err, "quarantine backoff must be positive, got 0s with a maximum of 0s")
+}
+
+func TestApplyChangesQuarantineHoldsBackOwnershipRecord(t *testing.T) {
+	t.Parallel()
+
+	fakeServer := fake.NewServer()
+	zone := fakeServer.AddZone("1234", "example.com")
+	_, err := fakeServer.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.1.1.1")
+	assert.NoError(t, err)
+	_, err = fakeServer.AddRecordSet(zone.Id, "a-www.example.com.", "TXT", 300, `"heritage=external-dns"`)
+	assert.NoError(t, err)
+	fakeServer.InjectFault(fake.Fault{
+		Operation:  fake.OperationDeleteRecordSet,
+		StatusCode: http.StatusBadRequest,
+		Message:    "invalid record",
+	})
+
+	stackitDnsProvider := getFakeTestProvider(t, fakeServer, func(config *Config) {
+		config.FailureMode = FailureModePartial
+		config.Quarantine = QuarantinePolicy{Threshold: 1, Backoff: time.Hour}
+	})
+
+	changes := &plan.Changes{Delete: []*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.example.com", "A", "1.1.1.1"),
+		endpoint.NewEndpoint("a-www.example.com", "TXT", `"heritage=external-dns"`),
+	}}
+
+	// the failed delete quarantines the record set and holds back the delete of its ownership record
+	err = stackitDnsProvider.ApplyChanges(context.Background(), changes)
+	assert.ErrorContains(t, err, "invalid record")
+	assert.Len(t, stackitDnsProvider.QuarantinedRecords(), 1)
+	assert.Equal(t, 1, fakeServer.Requests(fake.OperationDeleteRecordSet))
+
+	// the quarantined delete still holds back the delete of the ownership record
+	err = stackitDnsProvider.ApplyChanges(context.Background(), changes)
+	var partialErr *PartialFailureError
+	assert.ErrorAs(t, err, &partialErr)
+	assert.Len(t, partialErr.Failures, 1)
+	assert.Equal(t, "a-www.example.com", partialErr.Failures[0].Name)
+	assert.ErrorIs(t, partialErr.Failures[0], errDependentChange)
+	assert.Equal(t, 1, fakeServer.Requests(fake.OperationDeleteRecordSet))
+	assert.Len(t, fakeServer.RecordSets(zone.Id), 2)
+}
@@ -0,0 +1,117 @@
+package stackitprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stackitcloud/stackit-sdk-go/core/clients"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+const (
+	// readinessCacheTTL is the duration for which the result of the readiness checks is reused, so that frequent
+	// probes do not cause requests to the API.
+	readinessCacheTTL = 30 * time.Second
+	// readinessTimeout is the timeout of all readiness checks together.
+	readinessTimeout = 5 * time.Second
+
+	checkToken     = "token"
+	checkListZones = "list-zones"
+)
+
+// tokenSource is implemented by the authentication flows of the STACKIT SDK.
+type tokenSource interface {
+	GetAccessToken() (string, error)
+}
+
+// readinessCache holds the result of the last readiness checks.
+type readinessCache struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	checks    []report.ReadinessCheck
+}
+
+// reset makes the next call check the readiness again.
+func (r *readinessCache) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = nil
+}
+
+// CheckReadiness checks whether an access token can be acquired and whether the zones of every project can be
+// listed. The result is cached, concurrent calls wait for the same check.
+func (d *StackitDNSProvider) CheckReadiness(ctx context.Context) []report.ReadinessCheck {
+	d.readiness.mu.Lock()
+	defer d.readiness.mu.Unlock()
+
+	if d.readiness.checks != nil && time.Since(d.readiness.checkedAt) < readinessCacheTTL {
+		return d.readiness.checks
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	checks := []report.ReadinessCheck{d.checkToken()}
+	for _, projectId := range d.projects.projectIds {
+		checks = append(checks, d.checkListZones(ctx, projectId))
+	}
+
+	d.readiness.checks = checks
+	d.readiness.checkedAt = time.Now()
+
+	return checks
+}
+
+// checkToken acquires an access token from the authentication flow of the API client, e.g. the key flow using the
+// service account key. The flow is found by unwrapping the middlewares of the transport.
+func (d *StackitDNSProvider) checkToken() report.ReadinessCheck {
+	var transport http.RoundTripper
+	if httpClient := d.apiClient.Load().GetConfig().HTTPClient; httpClient != nil {
+		transport = httpClient.Transport
+	}
+
+	for transport != nil {
+		switch flow := transport.(type) {
+		case tokenSource:
+			_, err := flow.GetAccessToken()
+
+			return newReadinessCheck(checkToken, err)
+		case *clients.TokenFlow:
+			// a static token cannot be acquired, it is only rejected by the API
+			if flow.GetConfig().ServiceAccountToken == "" {
+				return newReadinessCheck(checkToken, errors.New("no token configured"))
+			}
+
+			return newReadinessCheck(checkToken, nil)
+		}
+
+		wrapper, ok := transport.(interface{ Unwrap() http.RoundTripper })
+		if !ok {
+			break
+		}
+		transport = wrapper.Unwrap()
+	}
+
+	return newReadinessCheck(checkToken, errors.New("no authentication flow configured"))
+}
+
+// checkListZones lists a single zone of the project.
+func (d *StackitDNSProvider) checkListZones(ctx context.Context, projectId string) report.ReadinessCheck {
+	_, err := d.apiClient.Load().DefaultAPI.ListZones(ctx, projectId).Page(1).PageSize(1).Execute()
+
+	return newReadinessCheck(fmt.Sprintf("%s/%s", checkListZones, projectId), err)
+}
+
+func newReadinessCheck(name string, err error) report.ReadinessCheck {
+	if err != nil {
+		return report.ReadinessCheck{Name: name, Status: report.CheckStatusFailed, Error: err.Error()}
+	}
+
+	return report.ReadinessCheck{Name: name, Status: report.CheckStatusOK}
+}
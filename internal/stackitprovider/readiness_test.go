@@ -0,0 +1,135 @@
+package stackitprovider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit"
+)
+
+func TestCheckReadiness(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	var statusCode atomic.Int32
+	statusCode.Store(http.StatusOK)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/1234/zones", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		assert.Equal(t, "1", r.URL.Query().Get("pageSize"))
+
+		responseHandler(getValidResponseZoneAllBytes(t), int(statusCode.Load()))(w, r)
+	})
+
+	// the middlewares of the options wrap the authentication flow
+	stackitConfigOptions, err := stackit.SetConfigOptions(
+		context.Background(), server.URL, "token", "", "",
+		stackit.RetryOptions{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		stackit.RateLimitOptions{RequestsPerSecond: 100, Burst: 10},
+		nil,
+	)
+	assert.NoError(t, err)
+
+	stackitDnsProvider, err := NewStackitDNSProvider(zap.NewNop(), &Config{ProjectIds: []string{"1234"}, Workers: 1}, stackitConfigOptions...)
+	assert.NoError(t, err)
+
+	checks := stackitDnsProvider.CheckReadiness(context.Background())
+	assert.Equal(t, []report.ReadinessCheck{
+		{Name: "token", Status: report.CheckStatusOK},
+		{Name: "list-zones/1234", Status: report.CheckStatusOK},
+	}, checks)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// the result is cached
+	statusCode.Store(http.StatusUnauthorized)
+	checks = stackitDnsProvider.CheckReadiness(context.Background())
+	assert.Equal(t, report.CheckStatusOK, checks[1].Status)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// a new client is checked right away
+	err = stackitDnsProvider.UpdateAPIClient(stackitConfigOptions...)
+	assert.NoError(t, err)
+
+	checks = stackitDnsProvider.CheckReadiness(context.Background())
+	assert.Equal(t, report.CheckStatusOK, checks[0].Status)
+	assert.Equal(t, report.CheckStatusFailed, checks[1].Status)
+	assert.NotEmpty(t, checks[1].Error)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestCheckReadinessWithoutAuthFlow(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		responseHandler(getValidResponseZoneAllBytes(t), http.StatusOK)(w, r)
+	}))
+	defer server.Close()
+
+	stackitDnsProvider, err := NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{ProjectIds: []string{"1234"}, Workers: 1},
+		stackitconfig.WithEndpoint(server.URL),
+		stackitconfig.WithoutAuthentication(),
+		// a middleware which cannot be unwrapped hides the authentication flow
+		stackitconfig.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(next.RoundTrip)
+		}),
+	)
+	assert.NoError(t, err)
+
+	checks := stackitDnsProvider.CheckReadiness(context.Background())
+	assert.Equal(t, report.CheckStatusFailed, checks[0].Status)
+	assert.Equal(t, report.CheckStatusOK, checks[1].Status)
+}
+
+func TestCheckReadinessTokenFailure(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	stackitDnsProvider, err := NewStackitDNSProvider(
+		zap.NewNop(),
+		&Config{ProjectIds: []string{"1234"}, Workers: 1},
+		stackitconfig.WithEndpoint(server.URL),
+		stackitconfig.WithCustomAuth(failingAuthFlow{}),
+	)
+	assert.NoError(t, err)
+
+	checks := stackitDnsProvider.CheckReadiness(context.Background())
+	assert.Equal(t, report.ReadinessCheck{Name: "token", Status: report.CheckStatusFailed, Error: "invalid key"}, checks[0])
+	assert.Equal(t, report.CheckStatusFailed, checks[1].Status)
+}
+
+// failingAuthFlow is an authentication flow which is unable to acquire a token.
+type failingAuthFlow struct{}
+
+func (failingAuthFlow) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("invalid key")
+}
+
+func (failingAuthFlow) GetAccessToken() (string, error) {
+	return "", errors.New("invalid key")
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
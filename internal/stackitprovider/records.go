@@ -4,43 +4,54 @@ import (
 	"context"
 
 	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.opentelemetry.io/otel/trace"
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/provider"
 )
 
-const txtRecord = "TXT"
+const (
+	txtRecord = "TXT"
+	// commentProperty is the provider-specific property holding the comment of a record set. It is set with the
+	// annotation external-dns.alpha.kubernetes.io/webhook-stackit-comment.
+	commentProperty = "webhook/stackit-comment"
+)
 
 // Records returns resource records.
-func (d *StackitDNSProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+func (d *StackitDNSProvider) Records(ctx context.Context) (endpoints []*endpoint.Endpoint, err error) {
+	ctx, span := d.tracer.Start(ctx, "stackitprovider.Records")
+	defer func() { endSpan(span, err) }()
+
 	zones, err := d.zoneFetcherClient.zones(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	var endpoints []*endpoint.Endpoint
 	endpointsErrorChannel := make(chan endpointError, len(zones))
-	zoneIdsChannel := make(chan string, len(zones))
+	zonesChannel := make(chan *stackitdnsclient.Zone, len(zones))
 
 	for i := 0; i < d.workers; i++ {
-		go d.fetchRecordsWorker(ctx, zoneIdsChannel, endpointsErrorChannel)
+		go d.fetchRecordsWorker(ctx, zonesChannel, endpointsErrorChannel)
 	}
 
 	for i := range zones {
-		zone := &zones[i]
-		zoneIdsChannel <- zone.Id
+		zonesChannel <- &zones[i]
 	}
 
 	for i := 0; i < len(zones); i++ {
 		endpointsErrorList := <-endpointsErrorChannel
 		if endpointsErrorList.err != nil {
-			close(zoneIdsChannel)
+			close(zonesChannel)
 
 			return nil, endpointsErrorList.err
 		}
 		endpoints = append(endpoints, endpointsErrorList.endpoints...)
 	}
 
-	close(zoneIdsChannel)
+	close(zonesChannel)
+
+	d.comments.set(endpoints)
+	d.metrics.SetZones(len(zones))
+	d.collectSync(syncRecords)
 
 	return endpoints, nil
 }
@@ -48,11 +59,11 @@ func (d *StackitDNSProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 // fetchRecordsWorker fetches all records from a given zone.
 func (d *StackitDNSProvider) fetchRecordsWorker(
 	ctx context.Context,
-	zoneIdChannel chan string,
+	zonesChannel chan *stackitdnsclient.Zone,
 	endpointsErrorChannel chan<- endpointError,
 ) {
-	for zoneId := range zoneIdChannel {
-		d.processZoneRRSets(ctx, zoneId, endpointsErrorChannel)
+	for zone := range zonesChannel {
+		d.processZoneRRSets(ctx, zone, endpointsErrorChannel)
 	}
 
 	d.logger.Debug("fetch record set worker finished")
@@ -61,11 +72,17 @@ func (d *StackitDNSProvider) fetchRecordsWorker(
 // processZoneRRSets fetches and processes DNS records for a given zone.
 func (d *StackitDNSProvider) processZoneRRSets(
 	ctx context.Context,
-	zoneId string,
+	zone *stackitdnsclient.Zone,
 	endpointsErrorChannel chan<- endpointError,
 ) {
+	ctx, span := d.tracer.Start(ctx, "stackitprovider.fetchZoneRecords", trace.WithAttributes(
+		attributeZone.String(zone.DnsName),
+		attributeZoneId.String(zone.Id),
+	))
+
 	var endpoints []*endpoint.Endpoint
-	rrSets, err := d.rrSetFetcherClient.fetchRecords(ctx, zoneId, nil)
+	rrSets, err := d.rrSetFetcherClient.fetchRecords(ctx, zone.Id, nil)
+	endSpan(span, err)
 	if err != nil {
 		endpointsErrorChannel <- endpointError{
 			endpoints: nil,
@@ -75,6 +92,8 @@ func (d *StackitDNSProvider) processZoneRRSets(
 		return
 	}
 
+	d.metrics.SetRecords(zone.DnsName, countRecords(rrSets))
+
 	endpoints = d.collectEndPoints(rrSets)
 	endpointsErrorChannel <- endpointError{
 		endpoints: endpoints,
@@ -82,11 +101,13 @@ func (d *StackitDNSProvider) processZoneRRSets(
 	}
 }
 
-// collectEndPoints creates a list of Endpoints from the provided rrSets.
+// collectEndPoints creates a list of Endpoints from the provided rrSets. All records of a record set are
+// merged into a single endpoint, so that a (name, type) pair always maps to one endpoint with all of its targets.
 func (d *StackitDNSProvider) collectEndPoints(
 	rrSets []stackitdnsclient.RecordSet,
 ) []*endpoint.Endpoint {
 	var endpoints []*endpoint.Endpoint
+	endpointsByKey := make(map[endpoint.EndpointKey]*endpoint.Endpoint)
 
 	for i := range rrSets {
 		r := &rrSets[i]
@@ -96,12 +117,35 @@ func (d *StackitDNSProvider) collectEndPoints(
 			continue
 		}
 
-		endpoints = append(endpoints, endpointsFromRecords(name, recordType, ttl, records)...)
+		ep := endpointFromRecords(name, recordType, ttl, records)
+		if comment := r.GetComment(); comment != "" {
+			ep.WithProviderSpecific(commentProperty, comment)
+		}
+
+		key := ep.Key()
+		if existing, found := endpointsByKey[key]; found {
+			existing.Targets = append(existing.Targets, ep.Targets...)
+
+			continue
+		}
+
+		endpointsByKey[key] = ep
+		endpoints = append(endpoints, ep)
 	}
 
 	return endpoints
 }
 
+// countRecords returns the number of records in all record sets.
+func countRecords(rrSets []stackitdnsclient.RecordSet) int {
+	count := 0
+	for i := range rrSets {
+		count += len(rrSets[i].Records)
+	}
+
+	return count
+}
+
 func recordSetCoreFields(r *stackitdnsclient.RecordSet) (name string, recordType string, ttl endpoint.TTL, records []stackitdnsclient.Record, ok bool) {
 	if r == nil || len(r.Records) == 0 {
 		return "", "", 0, nil, false
@@ -110,8 +154,11 @@ func recordSetCoreFields(r *stackitdnsclient.RecordSet) (name string, recordType
 	return r.Name, string(r.Type), endpoint.TTL(r.Ttl), r.Records, true
 }
 
-func endpointsFromRecords(name, recordType string, ttl endpoint.TTL, records []stackitdnsclient.Record) []*endpoint.Endpoint {
-	endpoints := make([]*endpoint.Endpoint, 0, len(records))
+// endpointFromRecords creates a single endpoint holding the contents of all records as targets. The targets are
+// normalized like those of the desired endpoints in AdjustEndpoints, so that e.g. a CNAME target stored in mixed case
+// does not differ from the desired target forever.
+func endpointFromRecords(name, recordType string, ttl endpoint.TTL, records []stackitdnsclient.Record) *endpoint.Endpoint {
+	targets := make([]string, 0, len(records))
 
 	for i := range records {
 		rec := &records[i]
@@ -121,8 +168,8 @@ func endpointsFromRecords(name, recordType string, ttl endpoint.TTL, records []s
 			content = unformatTXTContent(content)
 		}
 
-		endpoints = append(endpoints, endpoint.NewEndpointWithTTL(name, recordType, ttl, content))
+		targets = append(targets, content)
 	}
 
-	return endpoints
+	return endpoint.NewEndpointWithTTL(name, recordType, ttl, normalizeTargets(recordType, targets)...)
 }
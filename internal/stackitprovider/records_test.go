@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/goccy/go-json"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"sigs.k8s.io/external-dns/endpoint"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/stackit/fake"
 )
 
 func TestRecords(t *testing.T) {
@@ -170,7 +173,7 @@ func TestZoneEndpoint403Records(t *testing.T) {
 	stackitDnsProvider, err := NewStackitDNSProvider(
 		zap.NewNop(),
 		&Config{
-			ProjectId:    "1234",
+			ProjectIds:   []string{"1234"},
 			DomainFilter: endpoint.DomainFilter{},
 			DryRun:       false,
 			Workers:      10,
@@ -190,7 +193,7 @@ func getDefaultTestProvider(server *httptest.Server) (*StackitDNSProvider, error
 	stackitDnsProvider, err := NewStackitDNSProvider(
 		zap.NewNop(),
 		&Config{
-			ProjectId:    "1234",
+			ProjectIds:   []string{"1234"},
 			DomainFilter: endpoint.DomainFilter{},
 			DryRun:       false,
 			Workers:      1,
@@ -203,6 +206,39 @@ func getDefaultTestProvider(server *httptest.Server) (*StackitDNSProvider, error
 	return stackitDnsProvider, err
 }
 
+// getFakeTestProvider returns a provider for the project 1234 of the fake STACKIT DNS API, whose configuration is
+// changed by modify. The options are added to the options of the API client. The fake is served until the test has
+// finished.
+func getFakeTestProvider(
+	t *testing.T,
+	fakeServer *fake.Server,
+	modify func(config *Config),
+	options ...stackitconfig.ConfigurationOption,
+) *StackitDNSProvider {
+	t.Helper()
+
+	server := httptest.NewServer(fakeServer)
+	t.Cleanup(server.Close)
+
+	config := Config{
+		ProjectIds: []string{"1234"},
+		Workers:    1,
+	}
+	if modify != nil {
+		modify(&config)
+	}
+
+	options = append([]stackitconfig.ConfigurationOption{
+		stackitconfig.WithEndpoint(server.URL),
+		stackitconfig.WithToken("token"),
+	}, options...)
+
+	stackitDnsProvider, err := NewStackitDNSProvider(zap.NewNop(), &config, options...)
+	assert.NoError(t, err)
+
+	return stackitDnsProvider
+}
+
 func getZonesHandlerRecordsPaged(t *testing.T) http.HandlerFunc {
 	t.Helper()
 
@@ -371,3 +407,155 @@ func getServerRecords(t *testing.T) *httptest.Server {
 
 	return server
 }
+
+func TestCollectEndPointsMergesRecords(t *testing.T) {
+	t.Parallel()
+
+	stackitDnsProvider := &StackitDNSProvider{logger: zap.NewNop()}
+
+	rrSets := []stackitdnsclient.RecordSet{
+		{
+			Name: "multi.test.com.",
+			Type: "A",
+			Ttl:  int32(300),
+			Records: []stackitdnsclient.Record{
+				{Content: "1.2.3.4"},
+				{Content: "5.6.7.8"},
+				{Content: "9.10.11.12"},
+			},
+		},
+		{
+			Name: "txt.test.com.",
+			Type: "TXT",
+			Ttl:  int32(300),
+			Records: []stackitdnsclient.Record{
+				{Content: `"first"`},
+				{Content: `"sec" "ond"`},
+			},
+		},
+		{
+			Name:    "empty.test.com.",
+			Type:    "A",
+			Ttl:     int32(300),
+			Records: []stackitdnsclient.Record{},
+		},
+	}
+
+	endpoints := stackitDnsProvider.collectEndPoints(rrSets)
+	assert.Len(t, endpoints, 2)
+
+	assert.Equal(t, "multi.test.com", endpoints[0].DNSName)
+	assert.Equal(t, "A", endpoints[0].RecordType)
+	assert.Equal(t, endpoint.Targets{"1.2.3.4", "5.6.7.8", "9.10.11.12"}, endpoints[0].Targets)
+
+	assert.Equal(t, "txt.test.com", endpoints[1].DNSName)
+	assert.Equal(t, endpoint.Targets{`"first"`, `"second"`}, endpoints[1].Targets)
+}
+
+func TestCollectEndPointsNormalizesTargets(t *testing.T) {
+	t.Parallel()
+
+	stackitDnsProvider := &StackitDNSProvider{logger: zap.NewNop()}
+
+	rrSets := []stackitdnsclient.RecordSet{
+		{
+			Name:    "www.test.com.",
+			Type:    "CNAME",
+			Ttl:     int32(300),
+			Records: []stackitdnsclient.Record{{Content: "Target.Example.COM."}},
+		},
+		{
+			Name:    "test.com.",
+			Type:    "NS",
+			Ttl:     int32(300),
+			Records: []stackitdnsclient.Record{{Content: "NS1.Example.com."}, {Content: "ns1.example.com."}},
+		},
+	}
+
+	endpoints := stackitDnsProvider.collectEndPoints(rrSets)
+	assert.Len(t, endpoints, 2)
+	assert.Equal(t, endpoint.Targets{"target.example.com"}, endpoints[0].Targets)
+	assert.Equal(t, endpoint.Targets{"ns1.example.com"}, endpoints[1].Targets)
+
+	// the desired endpoint adjusted by external-dns does not differ from the current one
+	desired, err := stackitDnsProvider.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpoint("www.test.com", "CNAME", "target.example.COM."),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, endpoints[0].Targets, desired[0].Targets)
+}
+
+func TestCollectEndPointsComment(t *testing.T) {
+	t.Parallel()
+
+	stackitDnsProvider := &StackitDNSProvider{logger: zap.NewNop()}
+
+	rrSets := []stackitdnsclient.RecordSet{
+		{
+			Name:    "commented.test.com.",
+			Type:    "A",
+			Ttl:     int32(300),
+			Comment: new("ticket DNS-42"),
+			Records: []stackitdnsclient.Record{{Content: "1.2.3.4"}},
+		},
+		{
+			Name:    "plain.test.com.",
+			Type:    "A",
+			Ttl:     int32(300),
+			Comment: new(""),
+			Records: []stackitdnsclient.Record{{Content: "1.2.3.4"}},
+		},
+	}
+
+	endpoints := stackitDnsProvider.collectEndPoints(rrSets)
+	assert.Len(t, endpoints, 2)
+
+	comment, ok := endpoints[0].GetProviderSpecificProperty(commentProperty)
+	assert.True(t, ok)
+	assert.Equal(t, "ticket DNS-42", comment)
+	assert.Empty(t, endpoints[1].ProviderSpecific)
+}
+
+func TestCollectEndPointsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	stackitDnsProvider := &StackitDNSProvider{logger: zap.NewNop()}
+	longTXT := `"` + strings.Repeat("a", 255) + `" "b"`
+
+	rrSets := []stackitdnsclient.RecordSet{
+		{
+			Name: "multi.test.com.",
+			Type: "A",
+			Ttl:  int32(300),
+			Records: []stackitdnsclient.Record{
+				{Content: "1.2.3.4"},
+				{Content: "5.6.7.8"},
+			},
+		},
+		{
+			Name: "txt.test.com.",
+			Type: "TXT",
+			Ttl:  int32(600),
+			Records: []stackitdnsclient.Record{
+				{Content: longTXT},
+			},
+		},
+	}
+
+	endpoints := stackitDnsProvider.collectEndPoints(rrSets)
+	assert.Len(t, endpoints, len(rrSets))
+
+	for i, ep := range endpoints {
+		modifyChange(ep)
+		payload := getStackitRecordSetPayload(ep)
+
+		assert.Equal(t, rrSets[i].Name, payload.Name)
+		assert.Equal(t, string(rrSets[i].Type), string(payload.Type))
+		assert.Equal(t, rrSets[i].Ttl, *payload.Ttl)
+		assert.Len(t, payload.Records, len(rrSets[i].Records))
+
+		for j := range payload.Records {
+			assert.Equal(t, rrSets[i].Records[j].Content, payload.Records[j].Content)
+		}
+	}
+}
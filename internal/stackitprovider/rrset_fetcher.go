@@ -10,36 +10,69 @@ import (
 )
 
 type rrSetFetcher struct {
-	apiClient    *stackitdnsclient.APIClient
+	apiClient    *apiClientRef
 	domainFilter endpoint.DomainFilter
-	projectId    string
+	projects     *zoneProjects
 	logger       *zap.Logger
+	cache        *rrSetCache
 }
 
 func newRRSetFetcher(
-	apiClient *stackitdnsclient.APIClient,
+	apiClient *apiClientRef,
 	domainFilter endpoint.DomainFilter,
-	projectId string,
+	projects *zoneProjects,
 	logger *zap.Logger,
+	cache *rrSetCache,
 ) *rrSetFetcher {
 	return &rrSetFetcher{
 		apiClient:    apiClient,
 		domainFilter: domainFilter,
-		projectId:    projectId,
+		projects:     projects,
 		logger:       logger,
+		cache:        cache,
 	}
 }
 
 // fetchRecords fetches all []stackitdnsclient.RecordSet from STACKIT DNS API for given zone id.
+// Unfiltered results are served from and stored in the cache.
 func (r *rrSetFetcher) fetchRecords(
 	ctx context.Context,
 	zoneId string,
 	nameFilter *string,
 ) ([]stackitdnsclient.RecordSet, error) {
+	if nameFilter == nil {
+		if rrSets, ok := r.cache.getRRSets(zoneId); ok {
+			return rrSets, nil
+		}
+	}
+
+	result, err := r.fetchRecordsFromAPI(ctx, zoneId, nameFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	if nameFilter == nil {
+		r.cache.setRRSets(zoneId, result)
+	}
+
+	return result, nil
+}
+
+// fetchRecordsFromAPI fetches all pages of []stackitdnsclient.RecordSet from STACKIT DNS API for given zone id.
+func (r *rrSetFetcher) fetchRecordsFromAPI(
+	ctx context.Context,
+	zoneId string,
+	nameFilter *string,
+) ([]stackitdnsclient.RecordSet, error) {
+	projectId, err := r.projects.owner(zoneId)
+	if err != nil {
+		return nil, err
+	}
+
 	var result []stackitdnsclient.RecordSet
 	var pager int32 = 1
 
-	listRequest := r.apiClient.DefaultAPI.ListRecordSets(ctx, r.projectId, zoneId).Page(pager).PageSize(10000).ActiveEq(true)
+	listRequest := r.apiClient.Load().DefaultAPI.ListRecordSets(ctx, projectId, zoneId).Page(pager).PageSize(10000).ActiveEq(true)
 
 	if nameFilter != nil {
 		listRequest = listRequest.NameLike(*nameFilter)
@@ -67,33 +100,59 @@ func (r *rrSetFetcher) fetchRecords(
 	return result, nil
 }
 
-// getRRSetForUpdateDeletion returns the record set to be deleted and the zone it belongs to.
+// getRRSetForUpdateDeletion returns the record set to be deleted and the zone it belongs to. It returns whether the
+// record set was taken from the cache, otherwise it was just fetched from the API.
 func (r *rrSetFetcher) getRRSetForUpdateDeletion(
 	ctx context.Context,
 	change *endpoint.Endpoint,
 	zones []stackitdnsclient.Zone,
-) (*stackitdnsclient.Zone, *stackitdnsclient.RecordSet, error) {
+) (*stackitdnsclient.Zone, *stackitdnsclient.RecordSet, bool, error) {
 	resultZone, found := findBestMatchingZone(change.DNSName, zones)
 	if !found {
+		err := newNoMatchingZoneError(change.DNSName, zones)
 		r.logger.Error(
-			"record set name contains no zone dns name",
+			"no matching zone found",
 			zap.String("name", change.DNSName),
+			zap.Strings("candidates", err.candidates),
 		)
 
-		return nil, nil, fmt.Errorf("record set name contains no zone dns name")
+		return nil, nil, false, err
 	}
 
-	domainRRSets, err := r.fetchRecords(ctx, resultZone.Id, &change.DNSName)
-	if err != nil {
-		return nil, nil, err
+	// a cached copy of the whole zone saves the filtered request
+	domainRRSets, cached := r.cache.getRRSets(resultZone.Id)
+	if !cached {
+		var err error
+		domainRRSets, err = r.fetchRecords(ctx, resultZone.Id, &change.DNSName)
+		if err != nil {
+			return nil, nil, false, err
+		}
 	}
 
 	resultRRSet, found := findRRSet(change.DNSName, change.RecordType, domainRRSets)
 	if !found {
 		r.logger.Info("record not found on record sets", zap.String("name", change.DNSName))
 
-		return nil, nil, fmt.Errorf("record not found on record sets")
+		return nil, nil, false, fmt.Errorf("record not found on record sets")
 	}
 
-	return resultZone, resultRRSet, nil
+	return resultZone, resultRRSet, cached, nil
+}
+
+// getLiveRRSet fetches the record set with the given name and type from the API, bypassing the cache. It returns
+// whether the record set exists.
+func (r *rrSetFetcher) getLiveRRSet(
+	ctx context.Context,
+	zoneId string,
+	name string,
+	recordType string,
+) (*stackitdnsclient.RecordSet, bool, error) {
+	rrSets, err := r.fetchRecords(ctx, zoneId, &name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rrSet, found := findRRSet(name, recordType, rrSets)
+
+	return rrSet, found, nil
 }
@@ -1,24 +1,52 @@
 package stackitprovider
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
 	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
 	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/provider"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/audit"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics"
 )
 
 // StackitDNSProvider implements the DNS stackitprovider for STACKIT DNS.
 type StackitDNSProvider struct {
 	provider.BaseProvider
-	projectId          string
-	domainFilter       endpoint.DomainFilter
-	dryRun             bool
-	workers            int
-	logger             *zap.Logger
-	apiClient          *stackitdnsclient.APIClient
-	zoneFetcherClient  *zoneFetcher
-	rrSetFetcherClient *rrSetFetcher
+	projects              *zoneProjects
+	domainFilter          endpoint.DomainFilter
+	dryRun                bool
+	workers               int
+	deletionGuard         DeletionGuard
+	protection            *recordProtection
+	conflictPolicy        ConflictPolicy
+	optimisticConcurrency bool
+	failureMode           FailureMode
+	quarantine            *failureQuarantine
+	logger                *zap.Logger
+	apiClient             *apiClientRef
+	zoneFetcherClient     *zoneFetcher
+	rrSetFetcherClient    *rrSetFetcher
+	cache                 *rrSetCache
+	readiness             readinessCache
+	comments              recordComments
+	metrics               metrics.ProviderMetrics
+	auditLog              *audit.Log
+	tracer                trace.Tracer
+}
+
+// apiClientRef references the API client shared by the provider and its fetchers. The client can be replaced
+// while the provider is running, e.g. after the service account key was rotated.
+type apiClientRef struct {
+	atomic.Pointer[stackitdnsclient.APIClient]
 }
 
 // NewStackitDNSProvider creates a new STACKIT DNS stackitprovider.
@@ -27,21 +55,81 @@ func NewStackitDNSProvider(
 	providerConfig *Config,
 	stackitConfig ...stackitconfig.ConfigurationOption,
 ) (*StackitDNSProvider, error) {
-	apiClient, err := stackitdnsclient.NewAPIClient(stackitConfig...)
+	client, err := stackitdnsclient.NewAPIClient(stackitConfig...)
+	if err != nil {
+		return nil, err
+	}
+
+	apiClient := &apiClientRef{}
+	apiClient.Store(client)
+
+	var cache *rrSetCache
+	if providerConfig.CacheEnabled {
+		cache = newRRSetCache(providerConfig.CacheTTL)
+	}
+
+	projects := newZoneProjects(providerConfig.ProjectIds, providerConfig.DomainProjects)
+	if len(projects.projectIds) == 0 {
+		return nil, errors.New("at least one project id is required")
+	}
+
+	protection, err := newRecordProtection(providerConfig.ProtectedRecords)
 	if err != nil {
 		return nil, err
 	}
 
+	if policy := providerConfig.ConflictPolicy; policy != "" && !slices.Contains(ConflictPolicies, policy) {
+		return nil, fmt.Errorf("unsupported conflict policy %q, supported policies: %v", policy, ConflictPolicies)
+	}
+
+	if mode := providerConfig.FailureMode; mode != "" && !slices.Contains(FailureModes, mode) {
+		return nil, fmt.Errorf("unsupported failure mode %q, supported modes: %v", mode, FailureModes)
+	}
+
+	if err = providerConfig.Quarantine.validate(); err != nil {
+		return nil, err
+	}
+
+	providerMetrics := providerConfig.Metrics
+	if providerMetrics == nil {
+		providerMetrics = noopMetrics{}
+	}
+
 	provider := &StackitDNSProvider{
-		apiClient:          apiClient,
-		domainFilter:       providerConfig.DomainFilter,
-		dryRun:             providerConfig.DryRun,
-		projectId:          providerConfig.ProjectId,
-		workers:            providerConfig.Workers,
-		logger:             logger,
-		zoneFetcherClient:  newZoneFetcher(apiClient, providerConfig.DomainFilter, providerConfig.ProjectId),
-		rrSetFetcherClient: newRRSetFetcher(apiClient, providerConfig.DomainFilter, providerConfig.ProjectId, logger),
+		apiClient:             apiClient,
+		domainFilter:          providerConfig.DomainFilter,
+		dryRun:                providerConfig.DryRun,
+		projects:              projects,
+		workers:               providerConfig.Workers,
+		deletionGuard:         providerConfig.DeletionGuard,
+		protection:            protection,
+		conflictPolicy:        providerConfig.ConflictPolicy,
+		optimisticConcurrency: providerConfig.OptimisticConcurrency,
+		failureMode:           providerConfig.FailureMode,
+		quarantine:            newFailureQuarantine(providerConfig.Quarantine),
+		logger:                logger,
+		zoneFetcherClient:     newZoneFetcher(apiClient, providerConfig.DomainFilter, projects, cache),
+		rrSetFetcherClient:    newRRSetFetcher(apiClient, providerConfig.DomainFilter, projects, logger, cache),
+		cache:                 cache,
+		metrics:               providerMetrics,
+		auditLog:              providerConfig.AuditLog,
+		tracer:                otel.Tracer(tracerName),
 	}
 
 	return provider, nil
 }
+
+// UpdateAPIClient creates a new API client from the given options and replaces the current one with it. Requests
+// in flight finish with the previous client, all further requests use the new one. The current client is kept if
+// the new one cannot be created.
+func (d *StackitDNSProvider) UpdateAPIClient(stackitConfig ...stackitconfig.ConfigurationOption) error {
+	client, err := stackitdnsclient.NewAPIClient(stackitConfig...)
+	if err != nil {
+		return err
+	}
+
+	d.apiClient.Store(client)
+	d.readiness.reset()
+
+	return nil
+}
@@ -1 +1,56 @@
 package stackitprovider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAPIClient(t *testing.T) {
+	t.Parallel()
+
+	oldServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer oldServer.Close()
+
+	newServer := getServerRecords(t)
+	defer newServer.Close()
+
+	stackitDnsProvider, err := getDefaultTestProvider(oldServer)
+	assert.NoError(t, err)
+
+	_, err = stackitDnsProvider.Records(context.Background())
+	assert.Error(t, err)
+
+	err = stackitDnsProvider.UpdateAPIClient(
+		stackitconfig.WithHTTPClient(newServer.Client()),
+		stackitconfig.WithEndpoint(newServer.URL),
+		stackitconfig.WithToken("rotated-token"),
+	)
+	assert.NoError(t, err)
+
+	endpoints, err := stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, endpoints, 2)
+}
+
+func TestUpdateAPIClientKeepsClientOnError(t *testing.T) {
+	t.Parallel()
+
+	server := getServerRecords(t)
+	defer server.Close()
+
+	stackitDnsProvider, err := getDefaultTestProvider(server)
+	assert.NoError(t, err)
+
+	err = stackitDnsProvider.UpdateAPIClient(stackitconfig.WithServiceAccountKeyPath("/does/not/exist.json"))
+	assert.Error(t, err)
+
+	_, err = stackitDnsProvider.Records(context.Background())
+	assert.NoError(t, err)
+}
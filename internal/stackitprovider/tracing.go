@@ -0,0 +1,37 @@
+package stackitprovider
+
+import (
+	"context"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/stackitcloud/external-dns-stackit-webhook/internal/stackitprovider"
+
+// attributes of the spans of the provider
+const (
+	attributeZone       = attribute.Key("dns.zone")
+	attributeZoneId     = attribute.Key("dns.zone_id")
+	attributeName       = attribute.Key("dns.name")
+	attributeRecordType = attribute.Key("dns.record_type")
+	attributeAction     = attribute.Key("dns.action")
+	attributeChanges    = attribute.Key("dns.changes")
+)
+
+// endSpan ends the span and records the error, if any.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// setZoneAttributes adds the zone a change is applied to to the current span.
+func setZoneAttributes(ctx context.Context, zone *stackitdnsclient.Zone) {
+	trace.SpanFromContext(ctx).SetAttributes(attributeZone.String(zone.DnsName), attributeZoneId.String(zone.Id))
+}
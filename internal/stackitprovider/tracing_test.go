@@ -0,0 +1,90 @@
+package stackitprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestApplyChangesSpans(t *testing.T) {
+	t.Parallel()
+
+	createResponse, err := json.Marshal(getValidRecordSetResponse())
+	assert.NoError(t, err)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/1234/zones", getProjectZonesHandler(t, stackitdnsclient.Zone{Id: "zone", DnsName: "example.com"}))
+	mux.HandleFunc("/v1/projects/1234/zones/zone/rrsets", responseHandler(createResponse, http.StatusAccepted))
+
+	stackitDnsProvider, err := getDefaultTestProvider(server)
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	stackitDnsProvider.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	err = stackitDnsProvider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{{DNSName: "www.example.com", RecordType: "A", Targets: endpoint.Targets{"1.2.3.4"}}},
+	})
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+
+	worker, batch, applyChanges := spans[0], spans[1], spans[2]
+	assert.Equal(t, "stackitprovider.changeWorker", worker.Name())
+	assert.Equal(t, "stackitprovider.handleRRSetWithWorkers", batch.Name())
+	assert.Equal(t, "stackitprovider.ApplyChanges", applyChanges.Name())
+
+	assert.Equal(t, batch.SpanContext().SpanID(), worker.Parent().SpanID())
+	assert.Equal(t, applyChanges.SpanContext().SpanID(), batch.Parent().SpanID())
+	assert.Subset(t, worker.Attributes(), []attribute.KeyValue{
+		attributeAction.String(CREATE),
+		attributeName.String("www.example.com"),
+		attributeRecordType.String("A"),
+		attributeZone.String("example.com"),
+		attributeZoneId.String("zone"),
+	})
+}
+
+func TestRecordsSpansError(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/v1/projects/1234/zones", getProjectZonesHandler(t, stackitdnsclient.Zone{Id: "zone", DnsName: "example.com"}))
+	mux.HandleFunc("/v1/projects/1234/zones/zone/rrsets", responseHandler(nil, http.StatusForbidden))
+
+	stackitDnsProvider, err := getDefaultTestProvider(server)
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	stackitDnsProvider.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	_, err = stackitDnsProvider.Records(context.Background())
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "stackitprovider.fetchZoneRecords", spans[0].Name())
+	assert.Equal(t, "stackitprovider.Records", spans[1].Name())
+
+	for _, span := range spans {
+		assert.Equal(t, codes.Error, span.Status().Code)
+	}
+}
@@ -8,28 +8,71 @@ import (
 )
 
 type zoneFetcher struct {
-	apiClient    *stackitdnsclient.APIClient
+	apiClient    *apiClientRef
 	domainFilter endpoint.DomainFilter
-	projectId    string
+	projects     *zoneProjects
+	cache        *rrSetCache
 }
 
 func newZoneFetcher(
-	apiClient *stackitdnsclient.APIClient,
+	apiClient *apiClientRef,
 	domainFilter endpoint.DomainFilter,
-	projectId string,
+	projects *zoneProjects,
+	cache *rrSetCache,
 ) *zoneFetcher {
 	return &zoneFetcher{
 		apiClient:    apiClient,
 		domainFilter: domainFilter,
-		projectId:    projectId,
+		projects:     projects,
+		cache:        cache,
 	}
 }
 
-// zones returns filtered list of stackitdnsclient.Zone if filter is set.
+// zones returns filtered list of stackitdnsclient.Zone if filter is set. The result is served from
+// the cache as long as it is not expired.
 func (z *zoneFetcher) zones(ctx context.Context) ([]stackitdnsclient.Zone, error) {
+	if zones, ok := z.cache.getZones(); ok {
+		return zones, nil
+	}
+
+	zones, err := z.fetchFilteredZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	z.cache.setZones(zones)
+
+	return zones, nil
+}
+
+// fetchFilteredZones fetches the zones of all projects from the STACKIT DNS API and registers the project
+// owning each of them.
+func (z *zoneFetcher) fetchFilteredZones(ctx context.Context) ([]stackitdnsclient.Zone, error) {
+	var result []stackitdnsclient.Zone
+	for _, projectId := range z.projects.projectIds {
+		zones, err := z.fetchProjectZones(ctx, projectId)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, zone := range zones {
+			if !z.projects.allowsZone(projectId, zone.DnsName) {
+				continue
+			}
+
+			z.projects.setOwner(zone.Id, projectId)
+			result = append(result, zone)
+		}
+	}
+
+	return result, nil
+}
+
+// fetchProjectZones fetches the zones of a project from the STACKIT DNS API, sending one request per domain filter.
+func (z *zoneFetcher) fetchProjectZones(ctx context.Context, projectId string) ([]stackitdnsclient.Zone, error) {
 	if len(z.domainFilter.Filters) == 0 {
 		// no filters, return all zones
-		zones, err := z.fetchZones(new(z.apiClient.DefaultAPI.ListZones(ctx, z.projectId).ActiveEq(true)))
+		zones, err := z.fetchZones(new(z.apiClient.Load().DefaultAPI.ListZones(ctx, projectId).ActiveEq(true)))
 		if err != nil {
 			return nil, err
 		}
@@ -40,7 +83,7 @@ func (z *zoneFetcher) zones(ctx context.Context) ([]stackitdnsclient.Zone, error
 	var result []stackitdnsclient.Zone
 	// send one request per filter
 	for _, filter := range z.domainFilter.Filters {
-		zones, err := z.fetchZones(new(z.apiClient.DefaultAPI.ListZones(ctx, z.projectId).ActiveEq(true).DnsNameLike(filter)))
+		zones, err := z.fetchZones(new(z.apiClient.Load().DefaultAPI.ListZones(ctx, projectId).ActiveEq(true).DnsNameLike(filter)))
 		if err != nil {
 			return nil, err
 		}
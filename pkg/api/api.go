@@ -10,11 +10,13 @@ import (
 	"time"
 
 	"github.com/goccy/go-json"
+	"github.com/gofiber/adaptor/v2"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/helmet"
 	fiberlogger "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 	fiberrecover "github.com/gofiber/fiber/v2/middleware/recover"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 	"sigs.k8s.io/external-dns/provider"
 
@@ -24,6 +26,8 @@ import (
 type Api interface {
 	Listen(port string) error
 	Test(req *http.Request, msTimeout ...int) (resp *http.Response, err error)
+	// Handler returns the api as a net/http handler, e.g. to serve it with an httptest.Server.
+	Handler() http.Handler
 }
 
 type api struct {
@@ -35,6 +39,10 @@ func (a api) Test(req *http.Request, msTimeout ...int) (resp *http.Response, err
 	return a.app.Test(req, msTimeout...)
 }
 
+func (a api) Handler() http.Handler {
+	return adaptor.FiberApp(a.app)
+}
+
 func (a api) Listen(port string) error {
 	go func() {
 		err := a.app.Listen(fmt.Sprintf(":%s", port))
@@ -79,6 +87,10 @@ func New(logger *zap.Logger, middlewareCollector metrics.HttpApiMetrics, provide
 	registerAt(app, "/metrics")
 	app.Get("/healthz", Health)
 
+	// providers without readiness checks are always ready
+	readinessChecker, _ := provider.(ReadinessChecker)
+	app.Get("/readyz", readiness{checker: readinessChecker}.Ready)
+
 	app.Use(NewMetricsMiddleware(middlewareCollector))
 	app.Use(fiberlogger.New())
 	app.Use(pprof.New(pprof.Config{Prefix: "/pprof"}))
@@ -88,12 +100,15 @@ func New(logger *zap.Logger, middlewareCollector metrics.HttpApiMetrics, provide
 	webhookRoutes := webhook{
 		provider: provider,
 		logger:   logger,
+		tracer:   otel.Tracer(tracerName),
 	}
 
 	app.Get("/records", webhookRoutes.Records)
 	app.Get("/", webhookRoutes.GetDomainFilter)
 	app.Post("/records", webhookRoutes.ApplyChanges)
 	app.Post("/adjustendpoints", webhookRoutes.AdjustEndpoints)
+	app.Post("/plan", webhookRoutes.Plan)
+	app.Get("/quarantine", webhookRoutes.Quarantine)
 
 	return &api{
 		logger: logger,
@@ -27,7 +27,9 @@ func (w webhook) ApplyChanges(ctx *fiber.Ctx) error {
 		zap.String("updateNew", fmt.Sprintf("%v", changes.UpdateNew)),
 	)
 
-	err = w.provider.ApplyChanges(ctx.UserContext(), &changes)
+	spanCtx, span := w.startSpan(ctx, "webhook.ApplyChanges")
+	err = w.provider.ApplyChanges(spanCtx, &changes)
+	endSpan(span, err)
 	if err != nil {
 		w.logger.Error("Error applying changes", zap.String(logFieldError, err.Error()))
 		ctx.Response().Header.Set(contentTypeHeader, contentTypePlaintext)
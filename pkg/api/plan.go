@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+// Planner is implemented by providers which can preview the API calls they would issue for a set of changes.
+type Planner interface {
+	// PlanChanges returns the API calls which ApplyChanges would issue for the changes, without issuing them.
+	PlanChanges(ctx context.Context, changes *plan.Changes) (*report.ChangePlan, error)
+}
+
+// ChangePlan is the response of the plan route.
+type ChangePlan struct {
+	// Operations are the planned API calls in execution order.
+	Operations []PlannedOperation `json:"operations"`
+	// Errors is the number of operations which would fail.
+	Errors int `json:"errors"`
+	// Conflicts is the number of operations which would be skipped because of a conflict.
+	Conflicts int `json:"conflicts,omitempty"`
+	// Error is the reason why the change set would be refused as a whole, e.g. because it exceeds a deletion limit.
+	// None of the operations would be issued then.
+	Error string `json:"error,omitempty"`
+}
+
+// PlannedOperation is a single planned API call.
+type PlannedOperation struct {
+	// Batch is the position of the batch of the call in the execution order. Calls of the same batch run concurrently.
+	Batch int `json:"batch"`
+	// Action is the action of the change, CREATE, UPDATE or DELETE.
+	Action string `json:"action"`
+	// Operation is the API call, e.g. CreateRecordSet. It is empty if no call would be issued.
+	Operation   string   `json:"operation,omitempty"`
+	ProjectId   string   `json:"projectId,omitempty"`
+	ZoneId      string   `json:"zoneId,omitempty"`
+	Zone        string   `json:"zone,omitempty"`
+	RecordSetId string   `json:"recordSetId,omitempty"`
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	TTL         int64    `json:"ttl,omitempty"`
+	Records     []string `json:"records,omitempty"`
+	Comment     string   `json:"comment,omitempty"`
+	// Error is the reason why the call would not be issued or would fail.
+	Error string `json:"error,omitempty"`
+	// Skipped is the reason why the change is skipped on purpose, e.g. because the record set is protected.
+	Skipped string `json:"skipped,omitempty"`
+	// Conflict is the reason why the change would be skipped because the record set changed since the state the
+	// change is based on was read.
+	Conflict string `json:"conflict,omitempty"`
+}
+
+// newChangePlan converts the change plan of the provider to its JSON representation.
+func newChangePlan(changePlan *report.ChangePlan) ChangePlan {
+	operations := make([]PlannedOperation, 0, len(changePlan.Operations))
+	for i := range changePlan.Operations {
+		operation := &changePlan.Operations[i]
+		operations = append(operations, PlannedOperation{
+			Batch:       operation.Batch,
+			Action:      operation.Action,
+			Operation:   operation.Operation,
+			ProjectId:   operation.ProjectId,
+			ZoneId:      operation.ZoneId,
+			Zone:        operation.Zone,
+			RecordSetId: operation.RecordSetId,
+			Name:        operation.Name,
+			Type:        operation.Type,
+			TTL:         operation.TTL,
+			Records:     operation.Records,
+			Comment:     operation.Comment,
+			Error:       operation.Error,
+			Skipped:     operation.Skipped,
+			Conflict:    operation.Conflict,
+		})
+	}
+
+	return ChangePlan{
+		Operations: operations,
+		Errors:     changePlan.Errors,
+		Conflicts:  changePlan.Conflicts,
+		Error:      changePlan.Error,
+	}
+}
+
+// Plan godoc
+// @Summary Plan changes
+// @Description Returns the API calls which applying the changes would issue, without issuing them
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} ChangePlan
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Failure 501 {string} string
+// @Router /plan [post]
+// @Tags changes
+// post route.
+func (w webhook) Plan(ctx *fiber.Ctx) error {
+	planner, ok := w.provider.(Planner)
+	if !ok {
+		ctx.Response().Header.Set(contentTypeHeader, contentTypePlaintext)
+
+		return ctx.Status(fiber.StatusNotImplemented).SendString("the provider does not support planning changes")
+	}
+
+	var changes plan.Changes
+	err := ctx.BodyParser(&changes)
+	if err != nil {
+		w.logger.Error("Error parsing body", zap.String(logFieldError, err.Error()))
+		ctx.Response().Header.Set(contentTypeHeader, contentTypePlaintext)
+
+		return ctx.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	spanCtx, span := w.startSpan(ctx, "webhook.Plan")
+	changePlan, err := planner.PlanChanges(spanCtx, &changes)
+	endSpan(span, err)
+	if err != nil {
+		w.logger.Error("Error planning changes", zap.String(logFieldError, err.Error()))
+		ctx.Response().Header.Set(contentTypeHeader, contentTypePlaintext)
+
+		return ctx.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	return ctx.JSON(newChangePlan(changePlan))
+}
@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/plan"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/api"
+	mockprovider "github.com/stackitcloud/external-dns-stackit-webhook/pkg/api/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+// plannerProvider is a provider which can plan changes.
+type plannerProvider struct {
+	*mockprovider.MockProvider
+	changePlan *report.ChangePlan
+	err        error
+}
+
+func (p plannerProvider) PlanChanges(context.Context, *plan.Changes) (*report.ChangePlan, error) {
+	return p.changePlan, p.err
+}
+
+func TestWebhook_Plan(t *testing.T) {
+	t.Parallel()
+
+	body, err := json.Marshal(getValidPlanChanges())
+	assert.NoError(t, err)
+
+	changePlan := &report.ChangePlan{
+		Operations: []report.PlannedOperation{
+			{Action: "CREATE", Operation: "CreateRecordSet", ZoneId: "1234", Name: "test.com.", Type: "A", TTL: 300, Records: []string{"1.1.1.1"}},
+			{Batch: 1, Action: "DELETE", Operation: "DeleteRecordSet", Name: "old.org.", Type: "A", Error: "no matching zone"},
+			{Batch: 1, Action: "DELETE", Operation: "DeleteRecordSet", Name: "www.test.com.", Type: "A", Conflict: "changed"},
+		},
+		Errors:    1,
+		Conflicts: 1,
+	}
+	expected := api.ChangePlan{
+		Operations: []api.PlannedOperation{
+			{Action: "CREATE", Operation: "CreateRecordSet", ZoneId: "1234", Name: "test.com.", Type: "A", TTL: 300, Records: []string{"1.1.1.1"}},
+			{Batch: 1, Action: "DELETE", Operation: "DeleteRecordSet", Name: "old.org.", Type: "A", Error: "no matching zone"},
+			{Batch: 1, Action: "DELETE", Operation: "DeleteRecordSet", Name: "www.test.com.", Type: "A", Conflict: "changed"},
+		},
+		Errors:    1,
+		Conflicts: 1,
+	}
+
+	tests := []struct {
+		name           string
+		err            error
+		body           []byte
+		expectedStatus int
+	}{
+		{"Plan returned", nil, body, http.StatusOK},
+		{"Invalid body", nil, []byte(`{"Create":"invalid"}`), http.StatusBadRequest},
+		{"Provider returns error", fmt.Errorf("test error"), body, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			provider := plannerProvider{
+				MockProvider: mockprovider.NewMockProvider(ctrl),
+				changePlan:   changePlan,
+				err:          tt.err,
+			}
+
+			app := api.New(zap.NewNop(), getTestMockMetricsCollector(ctrl), provider)
+
+			req := httptest.NewRequest(http.MethodPost, "/plan", bytes.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			resp, err := app.Test(req, -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			respBody, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			var got api.ChangePlan
+			assert.NoError(t, json.Unmarshal(respBody, &got))
+			assert.Equal(t, expected, got)
+		})
+	}
+}
+
+func TestWebhook_PlanNotSupported(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	app := api.New(zap.NewNop(), getTestMockMetricsCollector(ctrl), mockprovider.NewMockProvider(ctrl))
+
+	req := httptest.NewRequest(http.MethodPost, "/plan", bytes.NewReader([]byte(`{}`)))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+	resp, err := app.Test(req, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
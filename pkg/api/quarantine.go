@@ -0,0 +1,66 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+// QuarantineLister is implemented by providers which quarantine record sets whose changes fail repeatedly.
+type QuarantineLister interface {
+	// QuarantinedRecords returns the quarantined record sets.
+	QuarantinedRecords() []report.QuarantinedRecord
+}
+
+// QuarantinedRecord is a record set whose changes are skipped after failing repeatedly.
+type QuarantinedRecord struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Action is the action of the failed change, CREATE, UPDATE or DELETE.
+	Action string `json:"action"`
+	// Failures is the number of consecutive failures of the change.
+	Failures int `json:"failures"`
+	// LastError is the reason of the last failure.
+	LastError string `json:"lastError"`
+	// RetryAt is the time after which the change is issued again.
+	RetryAt time.Time `json:"retryAt"`
+}
+
+// newQuarantinedRecords converts the quarantined record sets of the provider to their JSON representation.
+func newQuarantinedRecords(records []report.QuarantinedRecord) []QuarantinedRecord {
+	result := make([]QuarantinedRecord, 0, len(records))
+	for _, record := range records {
+		result = append(result, QuarantinedRecord{
+			Name:      record.Name,
+			Type:      record.Type,
+			Action:    record.Action,
+			Failures:  record.Failures,
+			LastError: record.LastError,
+			RetryAt:   record.RetryAt,
+		})
+	}
+
+	return result
+}
+
+// Quarantine godoc
+// @Summary List quarantined records
+// @Description Returns the record sets whose changes are skipped after failing repeatedly
+// @Produce  json
+// @Success 200 {array} QuarantinedRecord
+// @Failure 501 {string} string
+// @Router /quarantine [get]
+// @Tags changes
+// get route.
+func (w webhook) Quarantine(ctx *fiber.Ctx) error {
+	lister, ok := w.provider.(QuarantineLister)
+	if !ok {
+		ctx.Response().Header.Set(contentTypeHeader, contentTypePlaintext)
+
+		return ctx.Status(fiber.StatusNotImplemented).SendString("the provider does not support quarantining records")
+	}
+
+	return ctx.JSON(newQuarantinedRecords(lister.QuarantinedRecords()))
+}
@@ -0,0 +1,76 @@
+package api_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/api"
+	mockprovider "github.com/stackitcloud/external-dns-stackit-webhook/pkg/api/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+// quarantineProvider is a provider which quarantines records.
+type quarantineProvider struct {
+	*mockprovider.MockProvider
+	records []report.QuarantinedRecord
+}
+
+func (p quarantineProvider) QuarantinedRecords() []report.QuarantinedRecord {
+	return p.records
+}
+
+func TestWebhook_Quarantine(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	records := []report.QuarantinedRecord{{
+		Name:      "www.example.com.",
+		Type:      "CNAME",
+		Action:    "CREATE",
+		Failures:  3,
+		LastError: "invalid record",
+		RetryAt:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}}
+
+	provider := quarantineProvider{MockProvider: mockprovider.NewMockProvider(ctrl), records: records}
+	app := api.New(zap.NewNop(), getTestMockMetricsCollector(ctrl), provider)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/quarantine", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	var got []api.QuarantinedRecord
+	assert.NoError(t, json.Unmarshal(respBody, &got))
+	assert.Equal(t, []api.QuarantinedRecord{{
+		Name:      "www.example.com.",
+		Type:      "CNAME",
+		Action:    "CREATE",
+		Failures:  3,
+		LastError: "invalid record",
+		RetryAt:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}}, got)
+}
+
+func TestWebhook_QuarantineNotSupported(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	app := api.New(zap.NewNop(), getTestMockMetricsCollector(ctrl), mockprovider.NewMockProvider(ctrl))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/quarantine", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
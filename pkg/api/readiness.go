@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+const (
+	readinessStatusReady    = "ready"
+	readinessStatusNotReady = "not ready"
+)
+
+// ReadinessChecker is implemented by providers which can verify that they are able to serve requests.
+type ReadinessChecker interface {
+	// CheckReadiness returns the results of all readiness checks.
+	CheckReadiness(ctx context.Context) []report.ReadinessCheck
+}
+
+// ReadinessCheck is the result of a single readiness check.
+type ReadinessCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// newReadinessChecks converts the readiness checks of the provider to their JSON representation.
+func newReadinessChecks(checks []report.ReadinessCheck) []ReadinessCheck {
+	result := make([]ReadinessCheck, 0, len(checks))
+	for _, check := range checks {
+		result = append(result, ReadinessCheck{
+			Name:   check.Name,
+			Status: check.Status,
+			Error:  check.Error,
+		})
+	}
+
+	return result
+}
+
+// Readiness is the response of the readiness route.
+type Readiness struct {
+	Status string           `json:"status"`
+	Checks []ReadinessCheck `json:"checks"`
+}
+
+type readiness struct {
+	checker ReadinessChecker
+}
+
+// Ready godoc
+// @Summary Readiness route
+// @Description Readiness route, checking whether the provider is able to reach the DNS API
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} Readiness
+// @Failure 503 {object} Readiness
+// @Router /readyz [get]
+// @Tags health
+// get route.
+func (r readiness) Ready(c *fiber.Ctx) error {
+	response := Readiness{
+		Status: readinessStatusReady,
+		Checks: []ReadinessCheck{},
+	}
+
+	if r.checker != nil {
+		response.Checks = newReadinessChecks(r.checker.CheckReadiness(c.UserContext()))
+	}
+
+	c.Status(fiber.StatusOK)
+	for _, check := range response.Checks {
+		if check.Status != report.CheckStatusOK {
+			response.Status = readinessStatusNotReady
+			c.Status(fiber.StatusServiceUnavailable)
+
+			break
+		}
+	}
+
+	return c.JSON(response)
+}
@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/api"
+	mockprovider "github.com/stackitcloud/external-dns-stackit-webhook/pkg/api/mock"
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/report"
+)
+
+// readinessProvider is a provider with readiness checks.
+type readinessProvider struct {
+	*mockprovider.MockProvider
+	checks []report.ReadinessCheck
+}
+
+func (r readinessProvider) CheckReadiness(context.Context) []report.ReadinessCheck {
+	return r.checks
+}
+
+func TestReady(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		checks         []report.ReadinessCheck
+		expectedStatus int
+		expectedBody   api.Readiness
+	}{
+		{
+			name: "All checks passed",
+			checks: []report.ReadinessCheck{
+				{Name: "token", Status: report.CheckStatusOK},
+				{Name: "list-zones/1234", Status: report.CheckStatusOK},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: api.Readiness{
+				Status: "ready",
+				Checks: []api.ReadinessCheck{
+					{Name: "token", Status: report.CheckStatusOK},
+					{Name: "list-zones/1234", Status: report.CheckStatusOK},
+				},
+			},
+		},
+		{
+			name: "Check failed",
+			checks: []report.ReadinessCheck{
+				{Name: "token", Status: report.CheckStatusFailed, Error: "invalid key"},
+				{Name: "list-zones/1234", Status: report.CheckStatusOK},
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody: api.Readiness{
+				Status: "not ready",
+				Checks: []api.ReadinessCheck{
+					{Name: "token", Status: report.CheckStatusFailed, Error: "invalid key"},
+					{Name: "list-zones/1234", Status: report.CheckStatusOK},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+
+			provider := readinessProvider{
+				MockProvider: mockprovider.NewMockProvider(ctrl),
+				checks:       tt.checks,
+			}
+
+			app := api.New(zap.NewNop(), getTestMockMetricsCollector(ctrl), provider)
+
+			resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil), -1)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			var readiness api.Readiness
+			assert.NoError(t, json.Unmarshal(body, &readiness))
+			assert.Equal(t, tt.expectedBody, readiness)
+		})
+	}
+}
+
+func TestReadyWithoutChecks(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	app := api.New(zap.NewNop(), getTestMockMetricsCollector(ctrl), mockprovider.NewMockProvider(ctrl))
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/readyz", nil), -1)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
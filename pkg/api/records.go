@@ -8,7 +8,9 @@ import (
 )
 
 func (w webhook) Records(ctx *fiber.Ctx) error {
-	records, err := w.provider.Records(ctx.UserContext())
+	spanCtx, span := w.startSpan(ctx, "webhook.Records")
+	records, err := w.provider.Records(spanCtx)
+	endSpan(span, err)
 	if err != nil {
 		w.logger.Error("Error getting records", zap.String(logFieldError, err.Error()))
 
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/stackitcloud/external-dns-stackit-webhook/pkg/api"
+
+// startSpan starts the span of a webhook handler. It continues the trace of the request, if the caller sent a
+// trace context.
+func (w webhook) startSpan(ctx *fiber.Ctx, name string) (context.Context, trace.Span) {
+	carrier := propagation.HeaderCarrier(http.Header(ctx.GetReqHeaders()))
+	parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), carrier)
+
+	return w.tracer.Start(parent, name, trace.WithSpanKind(trace.SpanKindServer))
+}
+
+// endSpan ends the span of a webhook handler and records the error, if any.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
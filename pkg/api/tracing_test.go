@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+	"sigs.k8s.io/external-dns/endpoint"
+
+	mockprovider "github.com/stackitcloud/external-dns-stackit-webhook/pkg/api/mock"
+)
+
+func TestWebhookSpans(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	recorder := tracetest.NewSpanRecorder()
+
+	var providerSpan trace.SpanContext
+
+	mockProvider := mockprovider.NewMockProvider(ctrl)
+	mockProvider.EXPECT().Records(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]*endpoint.Endpoint, error) {
+		providerSpan = trace.SpanContextFromContext(ctx)
+
+		return nil, errors.New("error")
+	})
+
+	w := webhook{
+		provider: mockProvider,
+		logger:   zap.NewNop(),
+		tracer:   sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test"),
+	}
+
+	app := fiber.New()
+	app.Get("/records", w.Records)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/records", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "webhook.Records", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	// the span is passed to the provider
+	assert.Equal(t, spans[0].SpanContext(), providerSpan)
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"sigs.k8s.io/external-dns/provider"
 )
@@ -8,4 +9,5 @@ import (
 type webhook struct {
 	provider provider.Provider
 	logger   *zap.Logger
+	tracer   trace.Tracer
 }
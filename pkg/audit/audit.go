@@ -0,0 +1,86 @@
+// Package audit writes an append-only log of the mutations of record sets.
+//
+// Every create, update and delete issued by the webhook, or skipped in dry run mode, is written as a single JSON
+// line to all sinks of the log, e.g. to stdout and to a rotated file.
+package audit
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// results of a mutation
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultSkipped = "skipped"
+)
+
+// Entry is a single mutation of a record set.
+type Entry struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Action      string    `json:"action"`
+	ZoneId      string    `json:"zoneId"`
+	RecordSetId string    `json:"recordSetId,omitempty"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	// OldTargets are the records of the record set before an update or delete.
+	OldTargets []string `json:"oldTargets,omitempty"`
+	// NewTargets are the records of the record set after a create or update.
+	NewTargets []string `json:"newTargets,omitempty"`
+	TTL        int64    `json:"ttl,omitempty"`
+	DryRun     bool     `json:"dryRun"`
+	// Result is ResultSuccess, ResultFailure or ResultSkipped in dry run mode.
+	Result string `json:"result"`
+	// Error is the reason of a failure.
+	Error string `json:"error,omitempty"`
+}
+
+// Log writes audit entries to its sinks. A nil *Log writes nothing. It is safe for concurrent use.
+type Log struct {
+	mu    sync.Mutex
+	sinks []io.Writer
+	now   func() time.Time
+}
+
+// New returns a log writing to the given sinks.
+func New(sinks ...io.Writer) *Log {
+	return &Log{
+		sinks: sinks,
+		now:   time.Now,
+	}
+}
+
+// Record writes the entry as a single JSON line to all sinks. The timestamp is set to now unless it is set already.
+// An entry is written to the remaining sinks even if a sink fails.
+func (l *Log) Record(entry Entry) error {
+	if l == nil {
+		return nil
+	}
+
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = l.now().UTC()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, sink := range l.sinks {
+		if _, err := sink.Write(line); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
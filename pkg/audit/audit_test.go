@@ -0,0 +1,51 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	var first, second bytes.Buffer
+	log := New(&first, failingWriter{}, &second)
+	log.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	err := log.Record(Entry{
+		Action:      "UPDATE",
+		ZoneId:      "zone-1",
+		RecordSetId: "rrset-1",
+		Name:        "www.example.com.",
+		Type:        "A",
+		OldTargets:  []string{"1.1.1.1"},
+		NewTargets:  []string{"2.2.2.2"},
+		TTL:         300,
+		Result:      ResultSuccess,
+	})
+	assert.EqualError(t, err, "disk full")
+
+	expected := `{"timestamp":"2024-01-02T03:04:05Z","action":"UPDATE","zoneId":"zone-1","recordSetId":"rrset-1",` +
+		`"name":"www.example.com.","type":"A","oldTargets":["1.1.1.1"],"newTargets":["2.2.2.2"],"ttl":300,` +
+		`"dryRun":false,"result":"success"}` + "\n"
+	assert.Equal(t, expected, first.String())
+	assert.Equal(t, expected, second.String())
+}
+
+func TestRecordNilLog(t *testing.T) {
+	t.Parallel()
+
+	var log *Log
+	assert.NoError(t, log.Record(Entry{Action: "CREATE"}))
+}
@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a file which is rotated once it would exceed its maximum size. The rotated files are named after
+// the file with the suffix .1 for the most recent one, .2 for the one before and so on. The oldest files beyond the
+// maximum number of backups are deleted. It is safe for concurrent use.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingFile opens the file at the given path for appending, creating it if it does not exist. A maxSize of 0
+// disables the rotation.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	if maxSize < 0 || maxBackups < 0 {
+		return nil, errors.New("maximum size and backups must not be negative")
+	}
+
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write appends p to the file. The file is rotated first if p would exceed the maximum size. A single write is never
+// split across files.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Close closes the file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
+
+// open opens the file for appending and reads its current size.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	return nil
+}
+
+// rotate renames the file to the first backup, shifting the existing backups, and opens a new file. The file is
+// reopened even if the backups could not be renamed, so the next write tries again.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+
+	renameErr := f.shiftBackups()
+	if err := f.open(); err != nil {
+		return errors.Join(renameErr, err)
+	}
+
+	return renameErr
+}
+
+// shiftBackups renames every backup to the next one and the file to the first backup. The oldest backup is
+// replaced, or the file is deleted if no backups are kept.
+func (f *RotatingFile) shiftBackups() error {
+	if f.maxBackups == 0 {
+		return os.Remove(f.path)
+	}
+
+	for i := f.maxBackups - 1; i > 0; i-- {
+		err := os.Rename(f.backupPath(i), f.backupPath(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	return os.Rename(f.path, f.backupPath(1))
+}
+
+// backupPath returns the path of the i-th backup.
+func (f *RotatingFile) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
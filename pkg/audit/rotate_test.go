@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	assert.NoError(t, os.WriteFile(path, []byte("old\n"), 0o600))
+
+	file, err := NewRotatingFile(path, 10, 2)
+	assert.NoError(t, err)
+
+	// the existing content counts towards the size
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := file.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, file.Close())
+
+	assertFileContent(t, path, "fourth\n")
+	assertFileContent(t, path+".1", "third\n")
+	assertFileContent(t, path+".2", "second\n")
+	assert.NoFileExists(t, path+".3")
+
+	_, err = file.Write([]byte("closed\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+}
+
+func TestRotatingFileWithoutBackups(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	file, err := NewRotatingFile(path, 10, 0)
+	assert.NoError(t, err)
+
+	for _, line := range []string{"first\n", "second\n"} {
+		_, err := file.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, file.Close())
+
+	assertFileContent(t, path, "second\n")
+	assert.NoFileExists(t, path+".1")
+}
+
+func assertFileContent(t *testing.T, path, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
@@ -0,0 +1,240 @@
+// Package config loads the configuration file of the webhook.
+//
+// The file is written in YAML or JSON. Its top-level keys are named after the command line flags, so every flag
+// can be set in the file as well. Command line flags and environment variables take precedence over the file.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// Version is the only supported version of the configuration file schema.
+const Version = 1
+
+// keyDelimiter separates nested keys in viper. It must not be a dot, since map keys like domain names contain dots.
+const keyDelimiter = "::"
+
+// File is the schema of the configuration file. The documentation of each key is the description of the flag
+// with the same name.
+type File struct {
+	// Version is the schema version of the file. It is required and must be 1.
+	Version int `mapstructure:"version"`
+
+	APIPort         string            `mapstructure:"api-port"`
+	AuthToken       string            `mapstructure:"auth-token"`
+	AuthKeyPath     string            `mapstructure:"auth-key-path"`
+	TokenURL        string            `mapstructure:"token-url"`
+	BaseURL         string            `mapstructure:"base-url"`
+	ProjectIDs      []string          `mapstructure:"project-id"`
+	ProjectDomains  map[string]string `mapstructure:"project-domain"`
+	Worker          int               `mapstructure:"worker"`
+	DomainFilter    []string          `mapstructure:"domain-filter"`
+	DryRun          bool              `mapstructure:"dry-run"`
+	LogLevel        string            `mapstructure:"log-level"`
+	CacheEnabled    bool              `mapstructure:"cache-enabled"`
+	CacheTTL        time.Duration     `mapstructure:"cache-ttl"`
+	RetryAttempts   int               `mapstructure:"retry-max-attempts"`
+	RetryBackoff    time.Duration     `mapstructure:"retry-initial-backoff"`
+	RetryMaxBackoff time.Duration     `mapstructure:"retry-max-backoff"`
+	RateLimitRPS    float64           `mapstructure:"rate-limit-rps"`
+	RateLimitBurst  int               `mapstructure:"rate-limit-burst"`
+	HotReload       bool              `mapstructure:"hot-reload"`
+	TracingEndpoint string            `mapstructure:"tracing-endpoint"`
+	TracingInsecure bool              `mapstructure:"tracing-insecure"`
+	TracingRatio    float64           `mapstructure:"tracing-sample-ratio"`
+
+	MaxDeletions            int                `mapstructure:"max-deletions"`
+	MaxDeletionsPercent     float64            `mapstructure:"max-deletions-percent"`
+	ZoneMaxDeletions        map[string]int     `mapstructure:"zone-max-deletions"`
+	ZoneMaxDeletionsPercent map[string]float64 `mapstructure:"zone-max-deletions-percent"`
+	AllowMassDeletion       bool               `mapstructure:"allow-mass-deletion"`
+	ProtectedNames          []string           `mapstructure:"protected-name"`
+	ProtectedTypes          []string           `mapstructure:"protected-type"`
+	UpdateConflictPolicy    string             `mapstructure:"update-conflict-policy"`
+	OptimisticConcurrency   bool               `mapstructure:"optimistic-concurrency"`
+	FailureMode             string             `mapstructure:"failure-mode"`
+	QuarantineThreshold     int                `mapstructure:"quarantine-threshold"`
+	QuarantineBackoff       time.Duration      `mapstructure:"quarantine-backoff"`
+	QuarantineMaxBackoff    time.Duration      `mapstructure:"quarantine-max-backoff"`
+	AuditLogStdout          bool               `mapstructure:"audit-log-stdout"`
+	AuditLogFile            string             `mapstructure:"audit-log-file"`
+	AuditLogMaxSize         int                `mapstructure:"audit-log-max-size"`
+	AuditLogMaxBackups      int                `mapstructure:"audit-log-max-backups"`
+
+	// settings holds the raw values of the keys set in the file.
+	settings map[string]any
+}
+
+// Load reads and validates the configuration file at the given path. The format is derived from the file extension.
+func Load(path string) (*File, error) {
+	v := viper.NewWithOptions(viper.KeyDelimiter(keyDelimiter))
+	v.SetConfigFile(path)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading config file %q: %w", path, err)
+	}
+
+	file := &File{settings: v.AllSettings()}
+	if err := v.UnmarshalExact(file); err != nil {
+		return nil, fmt.Errorf("decoding config file %q: %w", path, err)
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, fmt.Errorf("validating config file %q: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// IsSet returns whether the key is set in the file.
+func (f *File) IsSet(key string) bool {
+	_, ok := f.settings[key]
+
+	return ok
+}
+
+// ApplyToFlags sets all flags which are not changed yet to the values of the file.
+func (f *File) ApplyToFlags(flags *pflag.FlagSet) error {
+	var errs []error
+
+	flags.VisitAll(func(flag *pflag.Flag) {
+		value, ok := f.settings[flag.Name]
+		if flag.Changed || !ok {
+			return
+		}
+
+		if err := setFlag(flag, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: invalid value %v: %w", flag.Name, value, err))
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// validate checks the values of the keys set in the file.
+func (f *File) validate() error {
+	var errs []error
+
+	if !f.IsSet("version") {
+		errs = append(errs, errors.New("version: is required"))
+	} else if f.Version != Version {
+		errs = append(errs, fmt.Errorf("version: unsupported version %d, supported versions: [%d]", f.Version, Version))
+	}
+
+	if f.IsSet("worker") && f.Worker < 1 {
+		errs = append(errs, fmt.Errorf("worker: must be at least 1, got %d", f.Worker))
+	}
+
+	logLevels := []string{"debug", "info", "warn", "error"}
+	if f.IsSet("log-level") && !slices.Contains(logLevels, f.LogLevel) {
+		errs = append(errs, fmt.Errorf("log-level: must be one of %v, got %q", logLevels, f.LogLevel))
+	}
+
+	conflictPolicies := []string{"ignore", "flag", "refuse"}
+	if f.IsSet("update-conflict-policy") && !slices.Contains(conflictPolicies, f.UpdateConflictPolicy) {
+		errs = append(errs, fmt.Errorf("update-conflict-policy: must be one of %v, got %q", conflictPolicies, f.UpdateConflictPolicy))
+	}
+
+	failureModes := []string{"fail-fast", "partial"}
+	if f.IsSet("failure-mode") && !slices.Contains(failureModes, f.FailureMode) {
+		errs = append(errs, fmt.Errorf("failure-mode: must be one of %v, got %q", failureModes, f.FailureMode))
+	}
+
+	if f.IsSet("retry-max-attempts") && f.RetryAttempts < 1 {
+		errs = append(errs, fmt.Errorf("retry-max-attempts: must be at least 1, got %d", f.RetryAttempts))
+	}
+
+	for key, duration := range map[string]time.Duration{
+		"cache-ttl":              f.CacheTTL,
+		"retry-initial-backoff":  f.RetryBackoff,
+		"retry-max-backoff":      f.RetryMaxBackoff,
+		"quarantine-backoff":     f.QuarantineBackoff,
+		"quarantine-max-backoff": f.QuarantineMaxBackoff,
+	} {
+		if f.IsSet(key) && duration < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative, got %s", key, duration))
+		}
+	}
+
+	if f.IsSet("rate-limit-rps") && f.RateLimitRPS < 0 {
+		errs = append(errs, fmt.Errorf("rate-limit-rps: must not be negative, got %v", f.RateLimitRPS))
+	}
+
+	if f.IsSet("rate-limit-burst") && f.RateLimitBurst < 0 {
+		errs = append(errs, fmt.Errorf("rate-limit-burst: must not be negative, got %d", f.RateLimitBurst))
+	}
+
+	if f.IsSet("tracing-sample-ratio") && (f.TracingRatio < 0 || f.TracingRatio > 1) {
+		errs = append(errs, fmt.Errorf("tracing-sample-ratio: must be between 0 and 1, got %v", f.TracingRatio))
+	}
+
+	for key, value := range map[string]int{
+		"audit-log-max-size":    f.AuditLogMaxSize,
+		"audit-log-max-backups": f.AuditLogMaxBackups,
+		"quarantine-threshold":  f.QuarantineThreshold,
+	} {
+		if f.IsSet(key) && value < 0 {
+			errs = append(errs, fmt.Errorf("%s: must not be negative, got %d", key, value))
+		}
+	}
+
+	if f.IsSet("max-deletions") && f.MaxDeletions < 0 {
+		errs = append(errs, fmt.Errorf("max-deletions: must not be negative, got %d", f.MaxDeletions))
+	}
+
+	for zone, count := range f.ZoneMaxDeletions {
+		if count < 0 {
+			errs = append(errs, fmt.Errorf("zone-max-deletions: %s: must not be negative, got %d", zone, count))
+		}
+	}
+
+	if f.IsSet("max-deletions-percent") && (f.MaxDeletionsPercent < 0 || f.MaxDeletionsPercent > 100) {
+		errs = append(errs, fmt.Errorf("max-deletions-percent: must be between 0 and 100, got %v", f.MaxDeletionsPercent))
+	}
+
+	for zone, percent := range f.ZoneMaxDeletionsPercent {
+		if percent < 0 || percent > 100 {
+			errs = append(errs, fmt.Errorf("zone-max-deletions-percent: %s: must be between 0 and 100, got %v", zone, percent))
+		}
+	}
+
+	// sort the errors to report them in a stable order
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return errors.Join(errs...)
+}
+
+// setFlag sets the flag to the raw value of the file.
+func setFlag(flag *pflag.Flag, value any) error {
+	switch value := value.(type) {
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			values = append(values, fmt.Sprint(v))
+		}
+
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			return sliceValue.Replace(values)
+		}
+
+		return flag.Value.Set(strings.Join(values, ","))
+	case map[string]any:
+		pairs := make([]string, 0, len(value))
+		for k, v := range value {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", k, v))
+		}
+		sort.Strings(pairs)
+
+		return flag.Value.Set(strings.Join(pairs, ","))
+	default:
+		return flag.Value.Set(fmt.Sprint(value))
+	}
+}
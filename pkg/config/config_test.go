@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name: "YAML",
+			file: "config.yaml",
+			content: `
+version: 1
+project-id: [prod, staging]
+project-domain:
+  staging.example.com: staging
+worker: 5
+cache-ttl: 1m
+`,
+		},
+		{
+			name:    "JSON",
+			file:    "config.json",
+			content: `{"version": 1, "project-id": ["prod"], "dry-run": true}`,
+		},
+		{
+			name:    "Missing version",
+			file:    "config.yaml",
+			content: "worker: 5\n",
+			wantErr: "version: is required",
+		},
+		{
+			name:    "Unsupported version",
+			file:    "config.yaml",
+			content: "version: 2\n",
+			wantErr: "version: unsupported version 2, supported versions: [1]",
+		},
+		{
+			name:    "Unknown key",
+			file:    "config.yaml",
+			content: "version: 1\nworkers: 5\n",
+			wantErr: "invalid keys: workers",
+		},
+		{
+			name:    "Wrong type",
+			file:    "config.yaml",
+			content: "version: 1\nworker: many\n",
+			wantErr: "'worker'",
+		},
+		{
+			name:    "Invalid value",
+			file:    "config.yaml",
+			content: "version: 1\nlog-level: verbose\n",
+			wantErr: `log-level: must be one of [debug info warn error], got "verbose"`,
+		},
+		{
+			name:    "Invalid sample ratio",
+			file:    "config.yaml",
+			content: "version: 1\ntracing-sample-ratio: 2\n",
+			wantErr: "tracing-sample-ratio: must be between 0 and 1, got 2",
+		},
+		{
+			name: "Deletion limits",
+			file: "config.yaml",
+			content: `
+version: 1
+max-deletions: 100
+max-deletions-percent: 10
+zone-max-deletions:
+  example.com: 20
+zone-max-deletions-percent:
+  example.com: 2.5
+`,
+		},
+		{
+			name:    "Invalid zone deletion percentage",
+			file:    "config.yaml",
+			content: "version: 1\nzone-max-deletions-percent:\n  example.com: 150\n",
+			wantErr: "zone-max-deletions-percent: example.com: must be between 0 and 100, got 150",
+		},
+		{
+			name:    "Invalid failure mode",
+			file:    "config.yaml",
+			content: "version: 1\nfailure-mode: best-effort\n",
+			wantErr: `failure-mode: must be one of [fail-fast partial], got "best-effort"`,
+		},
+		{
+			name:    "Negative quarantine threshold",
+			file:    "config.yaml",
+			content: "version: 1\nquarantine-threshold: -1\n",
+			wantErr: "quarantine-threshold: must not be negative, got -1",
+		},
+		{
+			name:    "Invalid syntax",
+			file:    "config.yaml",
+			content: "version: [1\n",
+			wantErr: "reading config file",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeConfigFile(t, tt.file, tt.content)
+
+			_, err := Load(path)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadValues(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfigFile(t, "config.yaml", `
+version: 1
+project-id: [prod, staging]
+project-domain:
+  Staging.Example.com: staging
+cache-ttl: 1m
+rate-limit-rps: 2.5
+`)
+
+	file, err := Load(path)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"prod", "staging"}, file.ProjectIDs)
+	assert.Equal(t, map[string]string{"staging.example.com": "staging"}, file.ProjectDomains)
+	assert.Equal(t, time.Minute, file.CacheTTL)
+	assert.InDelta(t, 2.5, file.RateLimitRPS, 0)
+	assert.True(t, file.IsSet("cache-ttl"))
+	assert.False(t, file.IsSet("worker"))
+}
+
+func TestApplyToFlags(t *testing.T) {
+	t.Parallel()
+
+	path := writeConfigFile(t, "config.yaml", `
+version: 1
+project-id: [prod, staging]
+project-domain:
+  staging.example.com: staging
+domain-filter: [example.com, example.org]
+worker: 5
+log-level: debug
+cache-ttl: 1m
+`)
+
+	file, err := Load(path)
+	assert.NoError(t, err)
+
+	var (
+		projectIDs     []string
+		projectDomains map[string]string
+		domainFilter   []string
+		worker         int
+		logLevel       string
+		cacheTTL       time.Duration
+	)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringSliceVar(&projectIDs, "project-id", []string{}, "")
+	flags.StringToStringVar(&projectDomains, "project-domain", map[string]string{}, "")
+	flags.StringArrayVar(&domainFilter, "domain-filter", []string{}, "")
+	flags.IntVar(&worker, "worker", 10, "")
+	flags.StringVar(&logLevel, "log-level", "info", "")
+	flags.DurationVar(&cacheTTL, "cache-ttl", 5*time.Minute, "")
+
+	// flags set on the command line or by environment variables take precedence
+	assert.NoError(t, flags.Parse([]string{"--worker=20"}))
+
+	assert.NoError(t, file.ApplyToFlags(flags))
+
+	assert.Equal(t, []string{"prod", "staging"}, projectIDs)
+	assert.Equal(t, map[string]string{"staging.example.com": "staging"}, projectDomains)
+	assert.Equal(t, []string{"example.com", "example.org"}, domainFilter)
+	assert.Equal(t, 20, worker)
+	assert.Equal(t, "debug", logLevel)
+	assert.Equal(t, time.Minute, cacheTTL)
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
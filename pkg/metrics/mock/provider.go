@@ -0,0 +1,148 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./provider.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mock/provider.go -source=./provider.go ProviderMetrics
+//
+
+// Package mock_metrics is a generated GoMock package.
+package mock_metrics
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockProviderMetrics is a mock of ProviderMetrics interface.
+type MockProviderMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockProviderMetricsMockRecorder
+	isgomock struct{}
+}
+
+// MockProviderMetricsMockRecorder is the mock recorder for MockProviderMetrics.
+type MockProviderMetricsMockRecorder struct {
+	mock *MockProviderMetrics
+}
+
+// NewMockProviderMetrics creates a new mock instance.
+func NewMockProviderMetrics(ctrl *gomock.Controller) *MockProviderMetrics {
+	mock := &MockProviderMetrics{ctrl: ctrl}
+	mock.recorder = &MockProviderMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProviderMetrics) EXPECT() *MockProviderMetricsMockRecorder {
+	return m.recorder
+}
+
+// CollectAPICall mocks base method.
+func (m *MockProviderMetrics) CollectAPICall(operation, status string, duration float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectAPICall", operation, status, duration)
+}
+
+// CollectAPICall indicates an expected call of CollectAPICall.
+func (mr *MockProviderMetricsMockRecorder) CollectAPICall(operation, status, duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectAPICall", reflect.TypeOf((*MockProviderMetrics)(nil).CollectAPICall), operation, status, duration)
+}
+
+// CollectChange mocks base method.
+func (m *MockProviderMetrics) CollectChange(action, recordType, zone string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectChange", action, recordType, zone)
+}
+
+// CollectChange indicates an expected call of CollectChange.
+func (mr *MockProviderMetricsMockRecorder) CollectChange(action, recordType, zone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectChange", reflect.TypeOf((*MockProviderMetrics)(nil).CollectChange), action, recordType, zone)
+}
+
+// CollectDeletionGuardRejection mocks base method.
+func (m *MockProviderMetrics) CollectDeletionGuardRejection(zone string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectDeletionGuardRejection", zone)
+}
+
+// CollectDeletionGuardRejection indicates an expected call of CollectDeletionGuardRejection.
+func (mr *MockProviderMetricsMockRecorder) CollectDeletionGuardRejection(zone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectDeletionGuardRejection", reflect.TypeOf((*MockProviderMetrics)(nil).CollectDeletionGuardRejection), zone)
+}
+
+// CollectProtectedRecordSkip mocks base method.
+func (m *MockProviderMetrics) CollectProtectedRecordSkip(action, recordType string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectProtectedRecordSkip", action, recordType)
+}
+
+// CollectProtectedRecordSkip indicates an expected call of CollectProtectedRecordSkip.
+func (mr *MockProviderMetricsMockRecorder) CollectProtectedRecordSkip(action, recordType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectProtectedRecordSkip", reflect.TypeOf((*MockProviderMetrics)(nil).CollectProtectedRecordSkip), action, recordType)
+}
+
+// CollectUpdateConflict mocks base method.
+func (m *MockProviderMetrics) CollectUpdateConflict(zone, policy string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectUpdateConflict", zone, policy)
+}
+
+// CollectUpdateConflict indicates an expected call of CollectUpdateConflict.
+func (mr *MockProviderMetricsMockRecorder) CollectUpdateConflict(zone, policy any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectUpdateConflict", reflect.TypeOf((*MockProviderMetrics)(nil).CollectUpdateConflict), zone, policy)
+}
+
+// SetLastSuccessfulSync mocks base method.
+func (m *MockProviderMetrics) SetLastSuccessfulSync(operation string, timestamp float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetLastSuccessfulSync", operation, timestamp)
+}
+
+// SetLastSuccessfulSync indicates an expected call of SetLastSuccessfulSync.
+func (mr *MockProviderMetricsMockRecorder) SetLastSuccessfulSync(operation, timestamp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLastSuccessfulSync", reflect.TypeOf((*MockProviderMetrics)(nil).SetLastSuccessfulSync), operation, timestamp)
+}
+
+// SetQuarantinedRecords mocks base method.
+func (m *MockProviderMetrics) SetQuarantinedRecords(count int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetQuarantinedRecords", count)
+}
+
+// SetQuarantinedRecords indicates an expected call of SetQuarantinedRecords.
+func (mr *MockProviderMetricsMockRecorder) SetQuarantinedRecords(count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQuarantinedRecords", reflect.TypeOf((*MockProviderMetrics)(nil).SetQuarantinedRecords), count)
+}
+
+// SetRecords mocks base method.
+func (m *MockProviderMetrics) SetRecords(zone string, count int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRecords", zone, count)
+}
+
+// SetRecords indicates an expected call of SetRecords.
+func (mr *MockProviderMetricsMockRecorder) SetRecords(zone, count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRecords", reflect.TypeOf((*MockProviderMetrics)(nil).SetRecords), zone, count)
+}
+
+// SetZones mocks base method.
+func (m *MockProviderMetrics) SetZones(count int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetZones", count)
+}
+
+// SetZones indicates an expected call of SetZones.
+func (mr *MockProviderMetricsMockRecorder) SetZones(count any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetZones", reflect.TypeOf((*MockProviderMetrics)(nil).SetZones), count)
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./rate_limiter.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mock/rate_limiter.go -source=./rate_limiter.go RateLimiterMetrics
+//
+
+// Package mock_metrics is a generated GoMock package.
+package mock_metrics
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockRateLimiterMetrics is a mock of RateLimiterMetrics interface.
+type MockRateLimiterMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimiterMetricsMockRecorder
+	isgomock struct{}
+}
+
+// MockRateLimiterMetricsMockRecorder is the mock recorder for MockRateLimiterMetrics.
+type MockRateLimiterMetricsMockRecorder struct {
+	mock *MockRateLimiterMetrics
+}
+
+// NewMockRateLimiterMetrics creates a new mock instance.
+func NewMockRateLimiterMetrics(ctrl *gomock.Controller) *MockRateLimiterMetrics {
+	mock := &MockRateLimiterMetrics{ctrl: ctrl}
+	mock.recorder = &MockRateLimiterMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimiterMetrics) EXPECT() *MockRateLimiterMetricsMockRecorder {
+	return m.recorder
+}
+
+// CollectWaitDuration mocks base method.
+func (m *MockRateLimiterMetrics) CollectWaitDuration(duration float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectWaitDuration", duration)
+}
+
+// CollectWaitDuration indicates an expected call of CollectWaitDuration.
+func (mr *MockRateLimiterMetricsMockRecorder) CollectWaitDuration(duration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectWaitDuration", reflect.TypeOf((*MockRateLimiterMetrics)(nil).CollectWaitDuration), duration)
+}
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./reload.go
+//
+// Generated by this command:
+//
+//	mockgen -destination=./mock/reload.go -source=./reload.go ReloadMetrics
+//
+
+// Package mock_metrics is a generated GoMock package.
+package mock_metrics
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReloadMetrics is a mock of ReloadMetrics interface.
+type MockReloadMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockReloadMetricsMockRecorder
+	isgomock struct{}
+}
+
+// MockReloadMetricsMockRecorder is the mock recorder for MockReloadMetrics.
+type MockReloadMetricsMockRecorder struct {
+	mock *MockReloadMetrics
+}
+
+// NewMockReloadMetrics creates a new mock instance.
+func NewMockReloadMetrics(ctrl *gomock.Controller) *MockReloadMetrics {
+	mock := &MockReloadMetrics{ctrl: ctrl}
+	mock.recorder = &MockReloadMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReloadMetrics) EXPECT() *MockReloadMetricsMockRecorder {
+	return m.recorder
+}
+
+// CollectReload mocks base method.
+func (m *MockReloadMetrics) CollectReload(source string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectReload", source)
+}
+
+// CollectReload indicates an expected call of CollectReload.
+func (mr *MockReloadMetricsMockRecorder) CollectReload(source any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectReload", reflect.TypeOf((*MockReloadMetrics)(nil).CollectReload), source)
+}
+
+// CollectReloadFailure mocks base method.
+func (m *MockReloadMetrics) CollectReloadFailure(source string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CollectReloadFailure", source)
+}
+
+// CollectReloadFailure indicates an expected call of CollectReloadFailure.
+func (mr *MockReloadMetricsMockRecorder) CollectReloadFailure(source any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectReloadFailure", reflect.TypeOf((*MockReloadMetrics)(nil).CollectReloadFailure), source)
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ProviderMetrics is an interface that defines the methods that can be used to collect metrics of the calls to the
+// STACKIT API and of the DNS records managed by the provider.
+//
+//go:generate mockgen -destination=./mock/provider.go -source=./provider.go ProviderMetrics
+type ProviderMetrics interface {
+	// CollectAPICall increment the total calls to the STACKIT API and observe the histogram of their duration for
+	// the given operation and status
+	CollectAPICall(operation, status string, duration float64)
+	// CollectChange increment the total changes applied for the given action, record type and zone
+	CollectChange(action, recordType, zone string)
+	// SetZones set the number of managed zones
+	SetZones(count int)
+	// SetRecords set the number of records in the given zone
+	SetRecords(zone string, count int)
+	// SetLastSuccessfulSync set the unix timestamp of the last successful sync of the given operation
+	SetLastSuccessfulSync(operation string, timestamp float64)
+	// CollectDeletionGuardRejection increment the total change sets refused by the deletion guard for the given zone
+	CollectDeletionGuardRejection(zone string)
+	// CollectProtectedRecordSkip increment the total changes of protected record sets skipped for the given action
+	// and record type
+	CollectProtectedRecordSkip(action, recordType string)
+	// CollectUpdateConflict increment the total updates of record sets changed since external-dns read them for the
+	// given zone and conflict policy
+	CollectUpdateConflict(zone, policy string)
+	// SetQuarantinedRecords set the number of record sets quarantined after their changes failed repeatedly
+	SetQuarantinedRecords(count int)
+}
+
+// providerMetrics is a struct that implements the ProviderMetrics interface.
+type providerMetrics struct {
+	apiCalls           *prometheus.CounterVec
+	apiCallDuration    *prometheus.HistogramVec
+	changes            *prometheus.CounterVec
+	zones              prometheus.Gauge
+	records            *prometheus.GaugeVec
+	lastSuccessfulSync *prometheus.GaugeVec
+	deletionGuard      *prometheus.CounterVec
+	protectedSkips     *prometheus.CounterVec
+	updateConflicts    *prometheus.CounterVec
+	quarantinedRecords prometheus.Gauge
+}
+
+// CollectAPICall increment the total calls to the STACKIT API and observe the histogram of their duration for the
+// given operation and status.
+func (p *providerMetrics) CollectAPICall(operation, status string, duration float64) {
+	p.apiCalls.WithLabelValues(operation, status).Inc()
+	p.apiCallDuration.WithLabelValues(operation, status).Observe(duration)
+}
+
+// CollectChange increment the total changes applied for the given action, record type and zone.
+func (p *providerMetrics) CollectChange(action, recordType, zone string) {
+	p.changes.WithLabelValues(action, recordType, zone).Inc()
+}
+
+// SetZones set the number of managed zones.
+func (p *providerMetrics) SetZones(count int) {
+	p.zones.Set(float64(count))
+}
+
+// SetRecords set the number of records in the given zone.
+func (p *providerMetrics) SetRecords(zone string, count int) {
+	p.records.WithLabelValues(zone).Set(float64(count))
+}
+
+// SetLastSuccessfulSync set the unix timestamp of the last successful sync of the given operation.
+func (p *providerMetrics) SetLastSuccessfulSync(operation string, timestamp float64) {
+	p.lastSuccessfulSync.WithLabelValues(operation).Set(timestamp)
+}
+
+// CollectDeletionGuardRejection increment the total change sets refused by the deletion guard for the given zone.
+func (p *providerMetrics) CollectDeletionGuardRejection(zone string) {
+	p.deletionGuard.WithLabelValues(zone).Inc()
+}
+
+// CollectProtectedRecordSkip increment the total changes of protected record sets skipped for the given action and
+// record type.
+func (p *providerMetrics) CollectProtectedRecordSkip(action, recordType string) {
+	p.protectedSkips.WithLabelValues(action, recordType).Inc()
+}
+
+// CollectUpdateConflict increment the total updates of record sets changed since external-dns read them for the given
+// zone and conflict policy.
+func (p *providerMetrics) CollectUpdateConflict(zone, policy string) {
+	p.updateConflicts.WithLabelValues(zone, policy).Inc()
+}
+
+// SetQuarantinedRecords set the number of record sets quarantined after their changes failed repeatedly.
+func (p *providerMetrics) SetQuarantinedRecords(count int) {
+	p.quarantinedRecords.Set(float64(count))
+}
+
+// NewProviderMetrics returns a new instance of providerMetrics.
+func NewProviderMetrics() ProviderMetrics {
+	return &providerMetrics{
+		apiCalls: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stackit_api_requests_total",
+			Help: "Number of requests to the STACKIT API by operation and status",
+		}, []string{"operation", "status"}),
+		apiCallDuration: promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "stackit_api_request_duration_seconds",
+			Help:    "Duration of requests to the STACKIT API in seconds by operation and status",
+			Buckets: getBucketHttpMetrics(),
+		}, []string{"operation", "status"}),
+		changes: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stackit_provider_changes_total",
+			Help: "Number of applied record set changes by action, record type and zone",
+		}, []string{"action", "type", "zone"}),
+		zones: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "stackit_provider_zones",
+			Help: "Number of zones managed by the provider",
+		}),
+		records: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stackit_provider_records",
+			Help: "Number of records in a zone managed by the provider",
+		}, []string{"zone"}),
+		lastSuccessfulSync: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "stackit_provider_last_successful_sync_timestamp_seconds",
+			Help: "Unix timestamp of the last successful sync by operation",
+		}, []string{"operation"}),
+		deletionGuard: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stackit_provider_deletion_guard_rejections_total",
+			Help: "Number of change sets refused because they exceeded the deletion limit of a zone",
+		}, []string{"zone"}),
+		protectedSkips: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stackit_provider_protected_records_skipped_total",
+			Help: "Number of skipped changes of protected record sets by action and record type",
+		}, []string{"action", "type"}),
+		updateConflicts: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stackit_provider_update_conflicts_total",
+			Help: "Number of updates of record sets changed since external-dns read them by zone and conflict policy",
+		}, []string{"zone", "policy"}),
+		quarantinedRecords: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "stackit_provider_quarantined_records",
+			Help: "Number of record sets quarantined after their changes failed repeatedly",
+		}),
+	}
+}
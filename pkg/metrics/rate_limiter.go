@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// RateLimiterMetrics is an interface that defines the methods that can be used to collect metrics of the
+// client-side rate limiter for the STACKIT API.
+//
+//go:generate mockgen -destination=./mock/rate_limiter.go -source=./rate_limiter.go RateLimiterMetrics
+type RateLimiterMetrics interface {
+	// CollectWaitDuration observe the histogram of the time a request waited for the rate limiter
+	CollectWaitDuration(duration float64)
+}
+
+// rateLimiterMetrics is a struct that implements the RateLimiterMetrics interface.
+type rateLimiterMetrics struct {
+	waitDuration prometheus.Histogram
+}
+
+// CollectWaitDuration observe the histogram of the time a request waited for the rate limiter.
+func (r *rateLimiterMetrics) CollectWaitDuration(duration float64) {
+	r.waitDuration.Observe(duration)
+}
+
+// NewRateLimiterMetrics returns a new instance of rateLimiterMetrics.
+func NewRateLimiterMetrics() RateLimiterMetrics {
+	return &rateLimiterMetrics{
+		waitDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "stackit_api_rate_limiter_wait_duration_seconds",
+			Help:    "Time requests to the STACKIT API waited for the client-side rate limiter in seconds",
+			Buckets: getBucketHttpMetrics(),
+		}),
+	}
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ReloadMetrics is an interface that defines the methods that can be used to collect metrics of the hot reload of
+// the service account key and the configuration file.
+//
+//go:generate mockgen -destination=./mock/reload.go -source=./reload.go ReloadMetrics
+type ReloadMetrics interface {
+	// CollectReload increment the total reloads triggered by the given source
+	CollectReload(source string)
+	// CollectReloadFailure increment the total failed reloads triggered by the given source
+	CollectReloadFailure(source string)
+}
+
+// reloadMetrics is a struct that implements the ReloadMetrics interface.
+type reloadMetrics struct {
+	reloads        *prometheus.CounterVec
+	reloadFailures *prometheus.CounterVec
+}
+
+// CollectReload increment the total reloads triggered by the given source.
+func (r *reloadMetrics) CollectReload(source string) {
+	r.reloads.WithLabelValues(source).Inc()
+}
+
+// CollectReloadFailure increment the total failed reloads triggered by the given source.
+func (r *reloadMetrics) CollectReloadFailure(source string) {
+	r.reloadFailures.WithLabelValues(source).Inc()
+}
+
+// NewReloadMetrics returns a new instance of reloadMetrics.
+func NewReloadMetrics() ReloadMetrics {
+	return &reloadMetrics{
+		reloads: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stackit_webhook_reloads_total",
+			Help: "Number of successful reloads of the STACKIT API client",
+		}, []string{"source"}),
+		reloadFailures: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "stackit_webhook_reload_failures_total",
+			Help: "Number of failed reloads of the STACKIT API client",
+		}, []string{"source"}),
+	}
+}
@@ -0,0 +1,144 @@
+// Package reload watches files and reports changes of their content.
+package reload
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// Watcher watches files for changes of their content. It watches the directories of the files instead of the files
+// themselves, so it also notices files replaced by a rename or a symlink swap, as Kubernetes does it for mounted
+// secrets and config maps.
+type Watcher struct {
+	logger   *zap.Logger
+	watcher  *fsnotify.Watcher
+	debounce time.Duration
+
+	mu     sync.Mutex
+	hashes map[string][sha256.Size]byte
+	dirs   map[string]struct{}
+}
+
+// NewWatcher creates a new Watcher. Changes are reported once no further event happened for the debounce duration,
+// since writing or replacing a file usually causes several events.
+func NewWatcher(logger *zap.Logger, debounce time.Duration) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Watcher{
+		logger:   logger,
+		watcher:  watcher,
+		debounce: debounce,
+		hashes:   map[string][sha256.Size]byte{},
+		dirs:     map[string]struct{}{},
+	}, nil
+}
+
+// Add starts watching the file at the given path. Adding a file twice has no effect.
+func (w *Watcher) Add(path string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.hashes[path]; ok {
+		return nil
+	}
+
+	hash, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	if _, ok := w.dirs[dir]; !ok {
+		if err := w.watcher.Add(dir); err != nil {
+			return err
+		}
+		w.dirs[dir] = struct{}{}
+	}
+
+	w.hashes[path] = hash
+
+	return nil
+}
+
+// Run calls onChange with the paths of all files whose content changed, until the context is done.
+func (w *Watcher) Run(ctx context.Context, onChange func(paths []string)) {
+	defer w.watcher.Close()
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			w.logger.Debug("file event", zap.String("name", event.Name), zap.String("op", event.Op.String()))
+			timer.Reset(w.debounce)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Warn("error watching files", zap.Error(err))
+		case <-timer.C:
+			if paths := w.changedFiles(); len(paths) > 0 {
+				onChange(paths)
+			}
+		}
+	}
+}
+
+// changedFiles returns the paths of all files whose content changed since the last call. Files which cannot be
+// read, e.g. because they are just being replaced, are checked again on the next event.
+func (w *Watcher) changedFiles() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var changed []string
+	for path, oldHash := range w.hashes {
+		hash, err := hashFile(path)
+		if err != nil {
+			w.logger.Warn("unable to read watched file", zap.String("path", path), zap.Error(err))
+
+			continue
+		}
+
+		if hash != oldHash {
+			w.hashes[path] = hash
+			changed = append(changed, path)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(content), nil
+}
@@ -0,0 +1,94 @@
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	watched := filepath.Join(dir, "key.json")
+	other := filepath.Join(dir, "other.json")
+	assert.NoError(t, os.WriteFile(watched, []byte("old"), 0o600))
+
+	changes := startWatcher(t, watched)
+
+	// writing another file in the same directory is not reported
+	assert.NoError(t, os.WriteFile(other, []byte("other"), 0o600))
+	assertNoChange(t, changes)
+
+	// writing the same content again is not reported either
+	assert.NoError(t, os.WriteFile(watched, []byte("old"), 0o600))
+	assertNoChange(t, changes)
+
+	assert.NoError(t, os.WriteFile(watched, []byte("new"), 0o600))
+	assertChange(t, changes, watched)
+}
+
+func TestWatcherSymlinkSwap(t *testing.T) {
+	t.Parallel()
+
+	// mimic the layout of a mounted Kubernetes secret
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "v1"), 0o700))
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "v2"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "v1", "key.json"), []byte("old"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "v2", "key.json"), []byte("new"), 0o600))
+	assert.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "key.json"), filepath.Join(dir, "key.json")))
+
+	watched := filepath.Join(dir, "key.json")
+	changes := startWatcher(t, watched)
+
+	assert.NoError(t, os.Symlink("v2", filepath.Join(dir, "..data_tmp")))
+	assert.NoError(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	assertChange(t, changes, watched)
+}
+
+func startWatcher(t *testing.T, path string) <-chan []string {
+	t.Helper()
+
+	watcher, err := NewWatcher(zap.NewNop(), 20*time.Millisecond)
+	assert.NoError(t, err)
+	assert.NoError(t, watcher.Add(path))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	changes := make(chan []string, 10)
+	go watcher.Run(ctx, func(paths []string) {
+		changes <- paths
+	})
+
+	return changes
+}
+
+func assertChange(t *testing.T, changes <-chan []string, path string) {
+	t.Helper()
+
+	select {
+	case paths := <-changes:
+		assert.Equal(t, []string{path}, paths)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not reported")
+	}
+}
+
+func assertNoChange(t *testing.T, changes <-chan []string) {
+	t.Helper()
+
+	select {
+	case paths := <-changes:
+		t.Fatalf("unexpected change of %v", paths)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
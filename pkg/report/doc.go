@@ -0,0 +1,4 @@
+// Package report defines the results the provider reports beyond the records of external-dns: readiness checks,
+// change plans and quarantined record sets. The api package serves them as JSON, so that the provider does not
+// depend on the representation of its results.
+package report
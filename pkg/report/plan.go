@@ -0,0 +1,40 @@
+package report
+
+// ChangePlan is the plan of the API calls a change set would cause.
+type ChangePlan struct {
+	// Operations are the planned API calls in execution order.
+	Operations []PlannedOperation
+	// Errors is the number of operations which would fail.
+	Errors int
+	// Conflicts is the number of operations which would be skipped because of a conflict.
+	Conflicts int
+	// Error is the reason why the change set would be refused as a whole, e.g. because it exceeds a deletion limit.
+	// None of the operations would be issued then.
+	Error string
+}
+
+// PlannedOperation is a single planned API call.
+type PlannedOperation struct {
+	// Batch is the position of the batch of the call in the execution order. Calls of the same batch run concurrently.
+	Batch int
+	// Action is the action of the change, CREATE, UPDATE or DELETE.
+	Action string
+	// Operation is the API call, e.g. CreateRecordSet. It is empty if no call would be issued.
+	Operation   string
+	ProjectId   string
+	ZoneId      string
+	Zone        string
+	RecordSetId string
+	Name        string
+	Type        string
+	TTL         int64
+	Records     []string
+	Comment     string
+	// Error is the reason why the call would not be issued or would fail.
+	Error string
+	// Skipped is the reason why the change is skipped on purpose, e.g. because the record set is protected.
+	Skipped string
+	// Conflict is the reason why the change would be skipped because the record set changed since the state the
+	// change is based on was read.
+	Conflict string
+}
@@ -0,0 +1,17 @@
+package report
+
+import "time"
+
+// QuarantinedRecord is a record set whose changes are skipped after failing repeatedly.
+type QuarantinedRecord struct {
+	Name string
+	Type string
+	// Action is the action of the failed change, CREATE, UPDATE or DELETE.
+	Action string
+	// Failures is the number of consecutive failures of the change.
+	Failures int
+	// LastError is the reason of the last failure.
+	LastError string
+	// RetryAt is the time after which the change is issued again.
+	RetryAt time.Time
+}
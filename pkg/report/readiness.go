@@ -0,0 +1,17 @@
+package report
+
+const (
+	// CheckStatusOK is the status of a passed readiness check.
+	CheckStatusOK = "ok"
+	// CheckStatusFailed is the status of a failed readiness check.
+	CheckStatusFailed = "failed"
+)
+
+// ReadinessCheck is the result of a single readiness check.
+type ReadinessCheck struct {
+	Name string
+	// Status is CheckStatusOK or CheckStatusFailed.
+	Status string
+	// Error is the reason of a failed check.
+	Error string
+}
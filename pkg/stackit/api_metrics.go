@@ -0,0 +1,83 @@
+package stackit
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics"
+)
+
+// statusError is the status of a request which failed without a response.
+const statusError = "error"
+
+// newAPIMetricsMiddleware returns a middleware which collects the number and the duration of requests to the
+// STACKIT API by operation and status. Every attempt of a retried request is collected separately.
+func newAPIMetricsMiddleware(providerMetrics metrics.ProviderMetrics) stackitconfig.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &apiMetricsTransport{
+			next:    next,
+			metrics: providerMetrics,
+		}
+	}
+}
+
+// apiMetricsTransport is an http.RoundTripper collecting metrics of the requests it sends.
+type apiMetricsTransport struct {
+	next    http.RoundTripper
+	metrics metrics.ProviderMetrics
+}
+
+func (t *apiMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+
+	status := statusError
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	t.metrics.CollectAPICall(apiOperation(req.Method, req.URL.Path), status, time.Since(started).Seconds())
+
+	return resp, err
+}
+
+// Unwrap returns the wrapped http.RoundTripper.
+func (t *apiMetricsTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
+
+// apiOperation returns the name of the DNS API operation of a request, derived from its method and path. The
+// names match the methods of the STACKIT SDK.
+func apiOperation(method, path string) string {
+	// e.g. v1/projects/{projectId}/zones/{zoneId}/rrsets/{rrSetId}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 4 || segments[1] != "projects" || segments[3] != "zones" {
+		return "Other"
+	}
+
+	switch {
+	case len(segments) == 4 && method == http.MethodGet:
+		return "ListZones"
+	case len(segments) == 5 && method == http.MethodGet:
+		return "GetZone"
+	case len(segments) == 6 && segments[5] == "rrsets" && method == http.MethodGet:
+		return "ListRecordSets"
+	case len(segments) == 6 && segments[5] == "rrsets" && method == http.MethodPost:
+		return "CreateRecordSet"
+	case len(segments) == 7 && segments[5] == "rrsets":
+		switch method {
+		case http.MethodGet:
+			return "GetRecordSet"
+		case http.MethodPatch:
+			return "PartialUpdateRecordSet"
+		case http.MethodDelete:
+			return "DeleteRecordSet"
+		}
+	}
+
+	return "Other"
+}
@@ -0,0 +1,75 @@
+package stackit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+)
+
+func TestAPIMetricsTransport(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	mockMetrics := mockmetrics.NewMockProviderMetrics(ctrl)
+	mockMetrics.EXPECT().CollectAPICall("ListRecordSets", "429", gomock.Any())
+	mockMetrics.EXPECT().CollectAPICall("DeleteRecordSet", "error", gomock.Any())
+
+	client := &http.Client{Transport: newAPIMetricsMiddleware(mockMetrics)(http.DefaultTransport)}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/v1/projects/p/zones/z/rrsets", nil)
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	failing := &http.Client{Transport: newAPIMetricsMiddleware(mockMetrics)(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	}))}
+
+	req, err = http.NewRequestWithContext(context.Background(), http.MethodDelete, server.URL+"/v1/projects/p/zones/z/rrsets/r", nil)
+	assert.NoError(t, err)
+	_, err = failing.Do(req)
+	assert.Error(t, err)
+}
+
+func TestAPIOperation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/v1/projects/p/zones", "ListZones"},
+		{http.MethodGet, "/v1/projects/p/zones/z", "GetZone"},
+		{http.MethodGet, "/v1/projects/p/zones/z/rrsets", "ListRecordSets"},
+		{http.MethodPost, "/v1/projects/p/zones/z/rrsets", "CreateRecordSet"},
+		{http.MethodGet, "/v1/projects/p/zones/z/rrsets/r", "GetRecordSet"},
+		{http.MethodPatch, "/v1/projects/p/zones/z/rrsets/r", "PartialUpdateRecordSet"},
+		{http.MethodDelete, "/v1/projects/p/zones/z/rrsets/r", "DeleteRecordSet"},
+		{http.MethodPost, "/token", "Other"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, apiOperation(tt.method, tt.path), "%s %s", tt.method, tt.path)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
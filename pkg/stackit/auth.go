@@ -0,0 +1,60 @@
+package stackit
+
+import (
+	"fmt"
+	"net/http"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+)
+
+// AuthError is returned if a request was not sent because no access token could be obtained from the
+// authentication flow of the client, for example since the service account key is invalid or the token
+// endpoint refused it.
+type AuthError struct {
+	// Err is the error of the authentication flow.
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("authentication failed: %v", e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// accessTokenProvider is implemented by the authentication flows of the SDK which obtain access tokens, like the
+// service account key flow.
+type accessTokenProvider interface {
+	GetAccessToken() (string, error)
+}
+
+// newAuthMiddleware returns a middleware which obtains the access token before the request is passed to the
+// authentication flow, so that a failure of the flow is reported as AuthError instead of a plain error which is
+// indistinguishable from a failed connection. It must wrap the authentication flow directly.
+func newAuthMiddleware() stackitconfig.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &authTransport{next: next}
+	}
+}
+
+// authTransport is an http.RoundTripper reporting failures of the authentication flow it wraps as AuthError.
+type authTransport struct {
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// the flow caches the token, so the flow itself gets the same token again when it sends the request
+	if flow, ok := t.next.(accessTokenProvider); ok {
+		if _, err := flow.GetAccessToken(); err != nil {
+			return nil, &AuthError{Err: err}
+		}
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// Unwrap returns the wrapped http.RoundTripper.
+func (t *authTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
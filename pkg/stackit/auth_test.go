@@ -0,0 +1,98 @@
+package stackit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAuthFlow is an authentication flow failing to obtain an access token if err is set.
+type fakeAuthFlow struct {
+	next http.RoundTripper
+	err  error
+}
+
+func (f *fakeAuthFlow) GetAccessToken() (string, error) {
+	return "token", f.err
+}
+
+func (f *fakeAuthFlow) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, err := f.GetAccessToken(); err != nil {
+		return nil, err
+	}
+
+	return f.next.RoundTrip(req)
+}
+
+func TestAuthTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		next    http.RoundTripper
+		wantErr bool
+	}{
+		{"Token obtained", &fakeAuthFlow{next: http.DefaultTransport}, false},
+		{"Token flow failed", &fakeAuthFlow{next: http.DefaultTransport, err: errors.New("get new access token")}, true},
+		{"Flow without tokens", http.DefaultTransport, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: newAuthMiddleware()(tt.next)}
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+			assert.NoError(t, err)
+
+			resp, err := client.Do(req)
+			if !tt.wantErr {
+				if assert.NoError(t, err) {
+					assert.Equal(t, http.StatusOK, resp.StatusCode)
+					_ = resp.Body.Close()
+				}
+
+				return
+			}
+
+			var authErr *AuthError
+			assert.ErrorAs(t, err, &authErr)
+			assert.EqualError(t, authErr, "authentication failed: get new access token")
+		})
+	}
+}
+
+func TestRetryTransportAuthError(t *testing.T) {
+	t.Parallel()
+
+	auth := newAuthMiddleware()(&fakeAuthFlow{next: http.DefaultTransport, err: errors.New("get new access token")})
+
+	attempts := 0
+	client := &http.Client{
+		Transport: newRetryMiddleware(RetryOptions{MaxAttempts: 3})(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+
+			return auth.RoundTrip(req)
+		})),
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://localhost", nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req)
+
+	var authErr *AuthError
+	assert.ErrorAs(t, err, &authErr)
+	assert.Equal(t, 1, attempts)
+}
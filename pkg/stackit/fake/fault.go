@@ -0,0 +1,125 @@
+package fake
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// Fault is an error or a delay injected into the responses of the server.
+type Fault struct {
+	// Operation restricts the fault to one operation, e.g. CreateRecordSet. It applies to all operations if empty.
+	Operation string
+	// Latency delays the response.
+	Latency time.Duration
+	// StatusCode is the status of the error returned instead of the response. No error is returned if it is zero.
+	StatusCode int
+	// Message is the message of the error.
+	Message string
+	// ErrorCode is sent in the error field of the error. The status text is sent if it is empty.
+	ErrorCode string
+	// RetryAfter is sent in the Retry-After header of the error, e.g. "1".
+	RetryAfter string
+	// Probability is the probability with which the fault applies to a request. It always applies if it is zero.
+	Probability float64
+	// Times is the number of requests the fault applies to. It applies to all requests if it is zero.
+	Times int
+}
+
+// RateLimitFault returns a fault answering requests with 429 Too Many Requests.
+func RateLimitFault(operation string) Fault {
+	return Fault{
+		Operation:  operation,
+		StatusCode: http.StatusTooManyRequests,
+		Message:    "too many requests",
+		RetryAfter: "1",
+	}
+}
+
+// ServerErrorFault returns a fault answering requests with 500 Internal Server Error.
+func ServerErrorFault(operation string) Fault {
+	return Fault{
+		Operation:  operation,
+		StatusCode: http.StatusInternalServerError,
+		Message:    "internal server error",
+	}
+}
+
+// QuotaFault returns a fault answering requests with the error of an exceeded record quota.
+func QuotaFault(operation string) Fault {
+	return Fault{
+		Operation:  operation,
+		StatusCode: http.StatusBadRequest,
+		Message:    QuotaExceededMessage,
+		ErrorCode:  QuotaExceededErrorCode,
+	}
+}
+
+// errorCode returns the error code sent in the error field of the error.
+func (f Fault) errorCode() string {
+	if f.ErrorCode != "" {
+		return f.ErrorCode
+	}
+
+	return http.StatusText(f.StatusCode)
+}
+
+// injectedFault is a fault added to the server with the number of requests it still applies to.
+type injectedFault struct {
+	Fault
+	remaining int
+}
+
+// matches returns whether the fault applies to a request of the given operation. It must be called with the lock
+// of the server held, since it counts the requests the fault applied to.
+func (f *injectedFault) matches(operation string) bool {
+	if f.Operation != "" && f.Operation != operation {
+		return false
+	}
+
+	if f.Times > 0 && f.remaining == 0 {
+		return false
+	}
+
+	// #nosec G404 -- the probability of a fault needs no cryptographic randomness
+	if f.Probability > 0 && rand.Float64() >= f.Probability {
+		return false
+	}
+
+	if f.Times > 0 {
+		f.remaining--
+	}
+
+	return true
+}
+
+// InjectFault adds a fault to the server. Faults are checked in the order they were added, the first one applying
+// to a request is used.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &injectedFault{Fault: fault, remaining: fault.Times})
+}
+
+// ClearFaults removes all faults from the server.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// fault returns the fault applying to a request of the given operation, if any.
+func (s *Server) fault(operation string) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.faults {
+		if f.matches(operation) {
+			return f.Fault, true
+		}
+	}
+
+	return Fault{}, false
+}
@@ -0,0 +1,657 @@
+// Package fake implements an in-memory fake of the STACKIT DNS API.
+//
+// The server implements the endpoints of the DNS API v1 used by the webhook: listing zones, listing, creating,
+// partially updating and deleting record sets, and a token endpoint for the key flow of the STACKIT SDK. Changes
+// are applied immediately, so record sets never are in a pending state. Faults like latency, rate limiting, server
+// errors and exceeded quotas can be injected per operation.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+)
+
+// QuotaExceededMessage is the message of the error returned if a change exceeds the record quota of a zone.
+const QuotaExceededMessage = "record quota of the zone exceeded"
+
+// QuotaExceededErrorCode is the error code sent instead of the status text in the error field of the error returned
+// if a change exceeds the record quota of a zone.
+const QuotaExceededErrorCode = "QuotaExceeded"
+
+// errQuotaExceeded is returned by addRecordSet if the record set exceeds the record quota of the zone.
+var errQuotaExceeded = errors.New(QuotaExceededMessage)
+
+// Operations of the DNS API, named after the methods of the STACKIT SDK.
+const (
+	OperationToken                  = "Token"
+	OperationListZones              = "ListZones"
+	OperationListRecordSets         = "ListRecordSets"
+	OperationCreateRecordSet        = "CreateRecordSet"
+	OperationPartialUpdateRecordSet = "PartialUpdateRecordSet"
+	OperationDeleteRecordSet        = "DeleteRecordSet"
+)
+
+// defaultPageSize is the page size of list requests without a page size, like in the DNS API.
+const defaultPageSize = 100
+
+// Server is an in-memory fake of the STACKIT DNS API. It implements http.Handler.
+type Server struct {
+	mux *http.ServeMux
+
+	mu          sync.Mutex
+	zones       map[string]*zone
+	faults      []*injectedFault
+	requests    map[string]int
+	recordQuota int
+	nextId      int
+}
+
+// zone is a zone of the server with its record sets.
+type zone struct {
+	projectId string
+	zone      stackitdnsclient.Zone
+	rrSets    map[string]*stackitdnsclient.RecordSet
+}
+
+// NewServer returns an empty server.
+func NewServer() *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		zones:    map[string]*zone{},
+		requests: map[string]int{},
+	}
+
+	s.handle("POST /token", OperationToken, s.token)
+	s.handle("GET /v1/projects/{projectId}/zones", OperationListZones, s.listZones)
+	s.handle("GET /v1/projects/{projectId}/zones/{zoneId}/rrsets", OperationListRecordSets, s.listRecordSets)
+	s.handle("POST /v1/projects/{projectId}/zones/{zoneId}/rrsets", OperationCreateRecordSet, s.createRecordSet)
+	s.handle("PATCH /v1/projects/{projectId}/zones/{zoneId}/rrsets/{rrSetId}", OperationPartialUpdateRecordSet, s.partialUpdateRecordSet)
+	s.handle("DELETE /v1/projects/{projectId}/zones/{zoneId}/rrsets/{rrSetId}", OperationDeleteRecordSet, s.deleteRecordSet)
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle registers the handler of an operation. Requests are counted, checked for faults and, except for the token
+// endpoint, for an access token before they are handled.
+func (s *Server) handle(pattern, operation string, handler http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[operation]++
+		s.mu.Unlock()
+
+		if fault, ok := s.fault(operation); ok {
+			if !sleep(r.Context(), fault.Latency) {
+				return
+			}
+
+			if fault.StatusCode != 0 {
+				if fault.RetryAfter != "" {
+					w.Header().Set("Retry-After", fault.RetryAfter)
+				}
+				writeErrorCode(w, fault.StatusCode, fault.errorCode(), fault.Message)
+
+				return
+			}
+		}
+
+		if operation != OperationToken && !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			writeError(w, http.StatusUnauthorized, "missing access token")
+
+			return
+		}
+
+		handler(w, r)
+	})
+}
+
+// Requests returns the number of requests of the given operation the server received, including failed ones.
+func (s *Server) Requests(operation string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[operation]
+}
+
+// SetRecordQuota sets the maximum number of records per zone. Zero disables the quota.
+func (s *Server) SetRecordQuota(quota int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recordQuota = quota
+}
+
+// AddZone adds an active zone with the given dns name to the project and returns it.
+func (s *Server) AddZone(projectId, dnsName string) stackitdnsclient.Zone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := timestamp()
+	z := stackitdnsclient.Zone{
+		Acl:               "0.0.0.0/0,::/0",
+		Active:            new(true),
+		CreationFinished:  now,
+		CreationStarted:   now,
+		DefaultTTL:        3600,
+		DnsName:           dnsName,
+		ExpireTime:        1209600,
+		Id:                s.newId("zone"),
+		Name:              dnsName,
+		NegativeCache:     60,
+		PrimaryNameServer: "ns1.stackit.cloud",
+		RecordCount:       new(int32(0)),
+		RefreshTime:       3600,
+		RetryTime:         600,
+		SerialNumber:      1,
+		State:             stackitdnsclient.ZONESTATE_CREATE_SUCCEEDED,
+		Type:              stackitdnsclient.ZONETYPE_PRIMARY,
+		UpdateFinished:    now,
+		UpdateStarted:     now,
+		Visibility:        stackitdnsclient.ZONEVISIBILITY_PUBLIC,
+	}
+
+	s.zones[z.Id] = &zone{projectId: projectId, zone: z, rrSets: map[string]*stackitdnsclient.RecordSet{}}
+
+	return z
+}
+
+// AddRecordSet adds a record set to the zone and returns it.
+func (s *Server) AddRecordSet(zoneId, name, recordType string, ttl int32, contents ...string) (stackitdnsclient.RecordSet, error) {
+	records := make([]stackitdnsclient.RecordPayload, 0, len(contents))
+	for _, content := range contents {
+		records = append(records, stackitdnsclient.RecordPayload{Content: content})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.zones[zoneId]
+	if !ok {
+		return stackitdnsclient.RecordSet{}, fmt.Errorf("zone %q not found", zoneId)
+	}
+
+	rrSet, status, err := s.addRecordSet(z, stackitdnsclient.CreateRecordSetPayload{
+		Name:    name,
+		Records: records,
+		Ttl:     &ttl,
+		Type:    stackitdnsclient.CreateRecordSetPayloadType(recordType),
+	})
+	if err != nil {
+		return stackitdnsclient.RecordSet{}, fmt.Errorf("%d: %w", status, err)
+	}
+
+	return *rrSet, nil
+}
+
+// UpdateRecordSet changes the record set with the given name and type in place, like a change made out of band,
+// e.g. in the portal. The name is the fully qualified name of the record set.
+func (s *Server) UpdateRecordSet(zoneId, name, recordType string, update func(rrSet *stackitdnsclient.RecordSet)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.zones[zoneId]
+	if !ok {
+		return fmt.Errorf("zone %q not found", zoneId)
+	}
+
+	for _, rrSet := range z.rrSets {
+		if rrSet.Name == name && string(rrSet.Type) == recordType {
+			update(rrSet)
+			rrSet.UpdateStarted = timestamp()
+			rrSet.UpdateFinished = rrSet.UpdateStarted
+			z.updateRecordCount()
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("record set %s %s not found", name, recordType)
+}
+
+// RemoveRecordSet removes the record set with the given name and type, like a deletion made out of band, e.g. in the
+// portal. The name is the fully qualified name of the record set.
+func (s *Server) RemoveRecordSet(zoneId, name, recordType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.zones[zoneId]
+	if !ok {
+		return fmt.Errorf("zone %q not found", zoneId)
+	}
+
+	for id, rrSet := range z.rrSets {
+		if rrSet.Name == name && string(rrSet.Type) == recordType {
+			delete(z.rrSets, id)
+			z.updateRecordCount()
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("record set %s %s not found", name, recordType)
+}
+
+// RecordSets returns the record sets of the zone ordered by name and type.
+func (s *Server) RecordSets(zoneId string) []stackitdnsclient.RecordSet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.zones[zoneId]
+	if !ok {
+		return nil
+	}
+
+	return z.sortedRRSets()
+}
+
+// token issues an access token for any assertion.
+func (s *Server) token(w http.ResponseWriter, _ *http.Request) {
+	accessToken, err := newAccessToken(time.Hour)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken:  accessToken,
+		ExpiresIn:    int(time.Hour.Seconds()),
+		RefreshToken: accessToken,
+		Scope:        "",
+		TokenType:    "Bearer",
+	})
+}
+
+func (s *Server) listZones(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	var zones []stackitdnsclient.Zone
+	for _, z := range s.zones {
+		if z.projectId != r.PathValue("projectId") ||
+			!matchesFilter(z.zone.DnsName, query.Get("dnsName[eq]"), query.Get("dnsName[like]")) {
+			continue
+		}
+
+		zones = append(zones, z.zone)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(zones, func(i, j int) bool { return zones[i].DnsName < zones[j].DnsName })
+
+	page, pageSize, ok := paging(w, r)
+	if !ok {
+		return
+	}
+
+	items, totalPages := paginate(zones, page, pageSize)
+	writeJSON(w, http.StatusOK, stackitdnsclient.ListZonesResponse{
+		ItemsPerPage: int32(pageSize), // #nosec G115 -- the page size is parsed as int32
+		TotalItems:   int32(len(zones)),
+		TotalPages:   totalPages,
+		Zones:        items,
+	})
+}
+
+func (s *Server) listRecordSets(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	s.mu.Lock()
+	z, ok := s.projectZone(r)
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "zone not found")
+
+		return
+	}
+
+	var rrSets []stackitdnsclient.RecordSet
+	for _, rrSet := range z.sortedRRSets() {
+		if !matchesFilter(rrSet.Name, query.Get("name[eq]"), query.Get("name[like]")) ||
+			(query.Get("type[eq]") != "" && string(rrSet.Type) != query.Get("type[eq]")) {
+			continue
+		}
+
+		rrSets = append(rrSets, rrSet)
+	}
+	s.mu.Unlock()
+
+	page, pageSize, ok := paging(w, r)
+	if !ok {
+		return
+	}
+
+	items, totalPages := paginate(rrSets, page, pageSize)
+	writeJSON(w, http.StatusOK, stackitdnsclient.ListRecordSetsResponse{
+		ItemsPerPage: int32(pageSize), // #nosec G115 -- the page size is parsed as int32
+		TotalItems:   int32(len(rrSets)),
+		TotalPages:   totalPages,
+		RrSets:       items,
+	})
+}
+
+func (s *Server) createRecordSet(w http.ResponseWriter, r *http.Request) {
+	var payload stackitdnsclient.CreateRecordSetPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.projectZone(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "zone not found")
+
+		return
+	}
+
+	rrSet, status, err := s.addRecordSet(z, payload)
+	if errors.Is(err, errQuotaExceeded) {
+		writeErrorCode(w, status, QuotaExceededErrorCode, err.Error())
+
+		return
+	}
+	if err != nil {
+		writeError(w, status, err.Error())
+
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, stackitdnsclient.RecordSetResponse{Rrset: *rrSet})
+}
+
+func (s *Server) partialUpdateRecordSet(w http.ResponseWriter, r *http.Request) {
+	var payload stackitdnsclient.PartialUpdateRecordSetPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.projectZone(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "zone not found")
+
+		return
+	}
+
+	rrSet, ok := z.rrSets[r.PathValue("rrSetId")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "record set not found")
+
+		return
+	}
+
+	if payload.Records != nil {
+		if len(payload.Records) == 0 {
+			writeError(w, http.StatusBadRequest, "records must not be empty")
+
+			return
+		}
+
+		if s.exceedsQuota(z, len(payload.Records)-len(rrSet.Records)) {
+			writeErrorCode(w, http.StatusBadRequest, QuotaExceededErrorCode, QuotaExceededMessage)
+
+			return
+		}
+
+		rrSet.Records = s.newRecords(payload.Records)
+	}
+	if payload.Name != nil {
+		rrSet.Name = *payload.Name
+	}
+	if payload.Ttl != nil {
+		rrSet.Ttl = *payload.Ttl
+	}
+	if payload.Comment != nil {
+		rrSet.Comment = payload.Comment
+		if *payload.Comment == "" {
+			rrSet.Comment = nil
+		}
+	}
+
+	rrSet.State = stackitdnsclient.RECORDSETSTATE_UPDATE_SUCCEEDED
+	rrSet.UpdateStarted = timestamp()
+	rrSet.UpdateFinished = rrSet.UpdateStarted
+	z.updateRecordCount()
+
+	writeJSON(w, http.StatusAccepted, stackitdnsclient.Message{Message: new("record set update accepted")})
+}
+
+func (s *Server) deleteRecordSet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	z, ok := s.projectZone(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "zone not found")
+
+		return
+	}
+
+	if _, ok := z.rrSets[r.PathValue("rrSetId")]; !ok {
+		writeError(w, http.StatusNotFound, "record set not found")
+
+		return
+	}
+
+	delete(z.rrSets, r.PathValue("rrSetId"))
+	z.updateRecordCount()
+
+	writeJSON(w, http.StatusAccepted, stackitdnsclient.Message{Message: new("record set deletion accepted")})
+}
+
+// addRecordSet validates the payload and adds the record set to the zone. It returns the status code of the error,
+// if the record set is invalid. It must be called with the lock held.
+func (s *Server) addRecordSet(z *zone, payload stackitdnsclient.CreateRecordSetPayload) (*stackitdnsclient.RecordSet, int, error) {
+	name := strings.TrimSuffix(payload.Name, ".")
+	zoneName := strings.TrimSuffix(z.zone.DnsName, ".")
+
+	switch {
+	case name != zoneName && !strings.HasSuffix(name, "."+zoneName):
+		return nil, http.StatusBadRequest, fmt.Errorf("name %q is not part of zone %q", payload.Name, z.zone.DnsName)
+	case payload.Type == "":
+		return nil, http.StatusBadRequest, errors.New("type is required")
+	case len(payload.Records) == 0:
+		return nil, http.StatusBadRequest, errors.New("records must not be empty")
+	}
+
+	for _, rrSet := range z.rrSets {
+		if strings.TrimSuffix(rrSet.Name, ".") == name && string(rrSet.Type) == string(payload.Type) {
+			return nil, http.StatusConflict, fmt.Errorf("record set %s %s already exists", payload.Name, payload.Type)
+		}
+	}
+
+	if s.exceedsQuota(z, len(payload.Records)) {
+		return nil, http.StatusBadRequest, errQuotaExceeded
+	}
+
+	ttl := z.zone.DefaultTTL
+	if payload.Ttl != nil {
+		ttl = *payload.Ttl
+	}
+
+	now := timestamp()
+	rrSet := &stackitdnsclient.RecordSet{
+		Active:           new(true),
+		Comment:          payload.Comment,
+		CreationFinished: now,
+		CreationStarted:  now,
+		Id:               s.newId("rrset"),
+		// the API always returns fully qualified names
+		Name:           name + ".",
+		Records:        s.newRecords(payload.Records),
+		State:          stackitdnsclient.RECORDSETSTATE_CREATE_SUCCEEDED,
+		Ttl:            ttl,
+		Type:           stackitdnsclient.RecordSetType(payload.Type),
+		UpdateFinished: now,
+		UpdateStarted:  now,
+	}
+
+	z.rrSets[rrSet.Id] = rrSet
+	z.updateRecordCount()
+
+	return rrSet, 0, nil
+}
+
+// exceedsQuota returns whether adding the given number of records exceeds the record quota of the zone. It must be
+// called with the lock held.
+func (s *Server) exceedsQuota(z *zone, added int) bool {
+	return s.recordQuota > 0 && int(*z.zone.RecordCount)+added > s.recordQuota
+}
+
+// newRecords returns the records of the payloads with new ids. It must be called with the lock held.
+func (s *Server) newRecords(payloads []stackitdnsclient.RecordPayload) []stackitdnsclient.Record {
+	records := make([]stackitdnsclient.Record, 0, len(payloads))
+	for _, payload := range payloads {
+		records = append(records, stackitdnsclient.Record{Content: payload.Content, Id: s.newId("record")})
+	}
+
+	return records
+}
+
+// newId returns a new unique id with the given prefix. It must be called with the lock held.
+func (s *Server) newId(prefix string) string {
+	s.nextId++
+
+	return fmt.Sprintf("%s-%d", prefix, s.nextId)
+}
+
+// projectZone returns the zone of the request, if it belongs to the project of the request. It must be called with
+// the lock held.
+func (s *Server) projectZone(r *http.Request) (*zone, bool) {
+	z, ok := s.zones[r.PathValue("zoneId")]
+	if !ok || z.projectId != r.PathValue("projectId") {
+		return nil, false
+	}
+
+	return z, true
+}
+
+// sortedRRSets returns copies of the record sets ordered by name and type.
+func (z *zone) sortedRRSets() []stackitdnsclient.RecordSet {
+	rrSets := make([]stackitdnsclient.RecordSet, 0, len(z.rrSets))
+	for _, rrSet := range z.rrSets {
+		copied := *rrSet
+		copied.Records = append([]stackitdnsclient.Record(nil), rrSet.Records...)
+		rrSets = append(rrSets, copied)
+	}
+
+	sort.Slice(rrSets, func(i, j int) bool {
+		if rrSets[i].Name != rrSets[j].Name {
+			return rrSets[i].Name < rrSets[j].Name
+		}
+
+		return rrSets[i].Type < rrSets[j].Type
+	})
+
+	return rrSets
+}
+
+// updateRecordCount sets the record count of the zone to the number of records in its record sets.
+func (z *zone) updateRecordCount() {
+	var count int32
+	for _, rrSet := range z.rrSets {
+		count += int32(len(rrSet.Records)) // #nosec G115 -- the number of records is limited by the body size
+	}
+
+	z.zone.RecordCount = &count
+}
+
+// matchesFilter returns whether the value equals eq and contains like, if they are set.
+func matchesFilter(value, eq, like string) bool {
+	if eq != "" && value != eq {
+		return false
+	}
+
+	return like == "" || strings.Contains(value, like)
+}
+
+// paging parses the page and the page size of a list request. It answers with 400 Bad Request if they are invalid.
+func paging(w http.ResponseWriter, r *http.Request) (page, pageSize int, ok bool) {
+	page, pageSize = 1, defaultPageSize
+
+	for param, value := range map[string]*int{"page": &page, "pageSize": &pageSize} {
+		raw := r.URL.Query().Get(param)
+		if raw == "" {
+			continue
+		}
+
+		parsed, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || parsed < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid %s %q", param, raw))
+
+			return 0, 0, false
+		}
+		*value = int(parsed)
+	}
+
+	return page, pageSize, true
+}
+
+// paginate returns the items of the page and the total number of pages.
+func paginate[T any](items []T, page, pageSize int) ([]T, int32) {
+	totalPages := int32((len(items) + pageSize - 1) / pageSize) // #nosec G115 -- the page size is at least 1
+
+	start := min((page-1)*pageSize, len(items))
+	end := min(start+pageSize, len(items))
+
+	return items[start:end], totalPages
+}
+
+// sleep waits for the duration. It returns false if the context is done before.
+func sleep(ctx context.Context, duration time.Duration) bool {
+	if duration <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	writeErrorCode(w, statusCode, http.StatusText(statusCode), message)
+}
+
+func writeErrorCode(w http.ResponseWriter, statusCode int, code, message string) {
+	writeJSON(w, statusCode, stackitdnsclient.ErrorMessage{
+		Error:   &code,
+		Message: &message,
+	})
+}
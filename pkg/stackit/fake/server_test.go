@@ -0,0 +1,301 @@
+package fake
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"github.com/stackitcloud/stackit-sdk-go/core/oapierror"
+	stackitdnsclient "github.com/stackitcloud/stackit-sdk-go/services/dns/v1api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListZones(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	fake.AddZone("project", "example.com")
+	fake.AddZone("project", "example.org")
+	fake.AddZone("other", "example.net")
+
+	client := newTestClient(t, fake)
+
+	resp, err := client.DefaultAPI.ListZones(context.Background(), "project").ActiveEq(true).Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.TotalItems)
+	assert.Equal(t, "example.com", resp.Zones[0].DnsName)
+	assert.Equal(t, "example.org", resp.Zones[1].DnsName)
+
+	resp, err = client.DefaultAPI.ListZones(context.Background(), "project").DnsNameLike("org").Execute()
+	assert.NoError(t, err)
+	assert.Len(t, resp.Zones, 1)
+	assert.Equal(t, "example.org", resp.Zones[0].DnsName)
+
+	resp, err = client.DefaultAPI.ListZones(context.Background(), "project").Page(2).PageSize(1).Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.TotalPages)
+	assert.Len(t, resp.Zones, 1)
+	assert.Equal(t, "example.org", resp.Zones[0].DnsName)
+}
+
+func TestRecordSetLifecycle(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	zone := fake.AddZone("project", "example.com")
+	client := newTestClient(t, fake)
+	ctx := context.Background()
+
+	created, err := client.DefaultAPI.CreateRecordSet(ctx, "project", zone.Id).CreateRecordSetPayload(stackitdnsclient.CreateRecordSetPayload{
+		Name:    "www.example.com.",
+		Records: []stackitdnsclient.RecordPayload{{Content: "1.2.3.4"}},
+		Ttl:     new(int32(300)),
+		Type:    "A",
+	}).Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, "www.example.com.", created.Rrset.Name)
+
+	// record sets are unique by name and type
+	_, err = client.DefaultAPI.CreateRecordSet(ctx, "project", zone.Id).CreateRecordSetPayload(stackitdnsclient.CreateRecordSetPayload{
+		Name:    "www.example.com",
+		Records: []stackitdnsclient.RecordPayload{{Content: "5.6.7.8"}},
+		Type:    "A",
+	}).Execute()
+	assertStatusCode(t, http.StatusConflict, err)
+
+	_, err = client.DefaultAPI.PartialUpdateRecordSet(ctx, "project", zone.Id, created.Rrset.Id).PartialUpdateRecordSetPayload(stackitdnsclient.PartialUpdateRecordSetPayload{
+		Comment: new("managed"),
+		Records: []stackitdnsclient.RecordPayload{{Content: "5.6.7.8"}, {Content: "9.9.9.9"}},
+	}).Execute()
+	assert.NoError(t, err)
+
+	list, err := client.DefaultAPI.ListRecordSets(ctx, "project", zone.Id).NameLike("www").Execute()
+	assert.NoError(t, err)
+	assert.Len(t, list.RrSets, 1)
+	assert.Equal(t, "managed", *list.RrSets[0].Comment)
+	assert.Equal(t, int32(300), list.RrSets[0].Ttl)
+	assert.Len(t, list.RrSets[0].Records, 2)
+
+	_, err = client.DefaultAPI.DeleteRecordSet(ctx, "project", zone.Id, created.Rrset.Id).Execute()
+	assert.NoError(t, err)
+	assert.Empty(t, fake.RecordSets(zone.Id))
+
+	_, err = client.DefaultAPI.DeleteRecordSet(ctx, "project", zone.Id, created.Rrset.Id).Execute()
+	assertStatusCode(t, http.StatusNotFound, err)
+
+	// zones are only found in their project
+	_, err = client.DefaultAPI.ListRecordSets(ctx, "other", zone.Id).Execute()
+	assertStatusCode(t, http.StatusNotFound, err)
+}
+
+func TestCreateRecordSetOutsideZone(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	zone := fake.AddZone("project", "example.com")
+
+	_, err := fake.AddRecordSet(zone.Id, "www.example.org.", "A", 300, "1.2.3.4")
+	assert.ErrorContains(t, err, "not part of zone")
+}
+
+func TestUpdateRecordSet(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	zone := fake.AddZone("project", "example.com")
+
+	_, err := fake.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.2.3.4")
+	assert.NoError(t, err)
+
+	err = fake.UpdateRecordSet(zone.Id, "www.example.com.", "A", func(rrSet *stackitdnsclient.RecordSet) {
+		rrSet.Comment = new("set in the portal")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, new("set in the portal"), fake.RecordSets(zone.Id)[0].Comment)
+
+	err = fake.UpdateRecordSet(zone.Id, "api.example.com.", "A", func(*stackitdnsclient.RecordSet) {})
+	assert.EqualError(t, err, "record set api.example.com. A not found")
+}
+
+func TestRemoveRecordSet(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	zone := fake.AddZone("project", "example.com")
+
+	_, err := fake.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.2.3.4")
+	assert.NoError(t, err)
+
+	assert.NoError(t, fake.RemoveRecordSet(zone.Id, "www.example.com.", "A"))
+	assert.Empty(t, fake.RecordSets(zone.Id))
+
+	err = fake.RemoveRecordSet(zone.Id, "www.example.com.", "A")
+	assert.EqualError(t, err, "record set www.example.com. A not found")
+}
+
+func TestRecordQuota(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	fake.SetRecordQuota(2)
+	zone := fake.AddZone("project", "example.com")
+	client := newTestClient(t, fake)
+
+	rrSet, err := fake.AddRecordSet(zone.Id, "www.example.com.", "A", 300, "1.2.3.4", "5.6.7.8")
+	assert.NoError(t, err)
+
+	_, err = client.DefaultAPI.CreateRecordSet(context.Background(), "project", zone.Id).CreateRecordSetPayload(stackitdnsclient.CreateRecordSetPayload{
+		Name:    "api.example.com.",
+		Records: []stackitdnsclient.RecordPayload{{Content: "1.2.3.4"}},
+		Type:    "A",
+	}).Execute()
+	assertStatusCode(t, http.StatusBadRequest, err)
+	assert.ErrorContains(t, err, QuotaExceededMessage)
+
+	var apiErr *oapierror.GenericOpenAPIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		var body stackitdnsclient.ErrorMessage
+		assert.NoError(t, json.Unmarshal(apiErr.Body, &body))
+		assert.Equal(t, new(QuotaExceededErrorCode), body.Error)
+	}
+
+	// replacing records within the quota is allowed
+	_, err = client.DefaultAPI.PartialUpdateRecordSet(context.Background(), "project", zone.Id, rrSet.Id).PartialUpdateRecordSetPayload(stackitdnsclient.PartialUpdateRecordSetPayload{
+		Records: []stackitdnsclient.RecordPayload{{Content: "9.9.9.9"}},
+	}).Execute()
+	assert.NoError(t, err)
+}
+
+func TestFaults(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	zone := fake.AddZone("project", "example.com")
+	client := newTestClient(t, fake)
+	ctx := context.Background()
+
+	rateLimit := RateLimitFault(OperationListRecordSets)
+	rateLimit.Times = 1
+	fake.InjectFault(rateLimit)
+	fake.InjectFault(ServerErrorFault(OperationDeleteRecordSet))
+	fake.InjectFault(Fault{Operation: OperationListZones, Latency: 50 * time.Millisecond})
+
+	_, err := client.DefaultAPI.ListRecordSets(ctx, "project", zone.Id).Execute()
+	assertStatusCode(t, http.StatusTooManyRequests, err)
+
+	// the rate limit only applied once
+	_, err = client.DefaultAPI.ListRecordSets(ctx, "project", zone.Id).Execute()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fake.Requests(OperationListRecordSets))
+
+	_, err = client.DefaultAPI.DeleteRecordSet(ctx, "project", zone.Id, "unknown").Execute()
+	assertStatusCode(t, http.StatusInternalServerError, err)
+
+	started := time.Now()
+	_, err = client.DefaultAPI.ListZones(ctx, "project").Execute()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(started), 50*time.Millisecond)
+
+	fake.ClearFaults()
+
+	_, err = client.DefaultAPI.DeleteRecordSet(ctx, "project", zone.Id, "unknown").Execute()
+	assertStatusCode(t, http.StatusNotFound, err)
+}
+
+func TestMissingToken(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(NewServer())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/v1/projects/project/zones")
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestKeyFlow(t *testing.T) {
+	t.Parallel()
+
+	fake := NewServer()
+	fake.AddZone("project", "example.com")
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	serviceAccountKey, privateKey := newTestServiceAccountKey(t)
+
+	client, err := stackitdnsclient.NewAPIClient(
+		stackitconfig.WithEndpoint(server.URL),
+		stackitconfig.WithTokenEndpoint(server.URL+"/token"),
+		stackitconfig.WithServiceAccountKey(serviceAccountKey),
+		stackitconfig.WithPrivateKey(privateKey),
+	)
+	assert.NoError(t, err)
+
+	resp, err := client.DefaultAPI.ListZones(context.Background(), "project").Execute()
+	assert.NoError(t, err)
+	assert.Len(t, resp.Zones, 1)
+	assert.Equal(t, 1, fake.Requests(OperationToken))
+}
+
+func newTestClient(t *testing.T, fake *Server) *stackitdnsclient.APIClient {
+	t.Helper()
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := stackitdnsclient.NewAPIClient(
+		stackitconfig.WithEndpoint(server.URL),
+		stackitconfig.WithToken("token"),
+	)
+	assert.NoError(t, err)
+
+	return client
+}
+
+func newTestServiceAccountKey(t *testing.T) (serviceAccountKey, privateKey string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	keyJSON, err := json.Marshal(map[string]any{
+		"id":           "b0b4fd57-d4a9-4a4b-9b61-0e1f2f5b3c51",
+		"active":       true,
+		"createdAt":    time.Now().Format(time.RFC3339),
+		"keyAlgorithm": "RSA_2048",
+		"keyOrigin":    "GENERATED",
+		"keyType":      "USER_MANAGED",
+		"publicKey":    "",
+		"credentials": map[string]any{
+			"aud": "https://stackit-service-account-prod.apps.01.cf.eu01.stackit.cloud",
+			"iss": "fake@sa.stackit.cloud",
+			"kid": "b0b4fd57-d4a9-4a4b-9b61-0e1f2f5b3c51",
+			"sub": "b0b4fd57-d4a9-4a4b-9b61-0e1f2f5b3c51",
+		},
+	})
+	assert.NoError(t, err)
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	return string(keyJSON), string(keyPEM)
+}
+
+func assertStatusCode(t *testing.T, statusCode int, err error) {
+	t.Helper()
+
+	var apiErr *oapierror.GenericOpenAPIError
+	if assert.True(t, errors.As(err, &apiErr), "expected an API error, got %v", err) {
+		assert.Equal(t, statusCode, apiErr.StatusCode)
+	}
+}
@@ -0,0 +1,31 @@
+package fake
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenSigningKey signs the access tokens. The tokens are not verified, the SDK only reads their expiry.
+var tokenSigningKey = []byte("fake")
+
+// tokenResponse is the response of the token endpoint.
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	TokenType    string `json:"token_type"`
+}
+
+// newAccessToken returns a JWT expiring after the given duration.
+func newAccessToken(validity time.Duration) (string, error) {
+	now := time.Now()
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    "stackit-fake",
+		Subject:   "fake-service-account",
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(validity)),
+	}).SignedString(tokenSigningKey)
+}
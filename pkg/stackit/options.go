@@ -4,28 +4,65 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"time"
 
 	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"go.opentelemetry.io/otel"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics"
 )
 
 // SetConfigOptions sets the default config options for the STACKIT
 // client and determines which type of authorization to use, depending on the
 // passed bearerToken and keyPath parameters. If no baseURL or an invalid
 // combination of auth options is given (neither or both), the function returns
-// an error.
-func SetConfigOptions(baseURL, bearerToken, keyPath, tokenURL string) ([]stackitconfig.ConfigurationOption, error) {
+// an error. Requests are rate limited according to rateLimitOptions and failed
+// requests are retried according to retryOptions. Every request attempt is
+// collected in providerMetrics, if given. Every call is traced with the global
+// OpenTelemetry tracer provider, including its retries. The token of a service
+// account key is refreshed in the background until ctx is done. Requests which
+// fail since no token could be obtained return an AuthError.
+func SetConfigOptions(
+	ctx context.Context,
+	baseURL, bearerToken, keyPath, tokenURL string,
+	retryOptions RetryOptions,
+	rateLimitOptions RateLimitOptions,
+	providerMetrics metrics.ProviderMetrics,
+) ([]stackitconfig.ConfigurationOption, error) {
 	if len(baseURL) == 0 {
 		return nil, fmt.Errorf("base-url is required")
 	}
 
 	options := []stackitconfig.ConfigurationOption{
 		stackitconfig.WithHTTPClient(&http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: retryOptions.timeout(),
 		}),
 		stackitconfig.WithEndpoint(baseURL),
 	}
 
+	// the last added middleware is executed first, so every retry waits for the rate limiter again and is
+	// collected in the metrics. The auth middleware is added first to wrap the authentication flow directly.
+	options = append(options, stackitconfig.WithMiddleware(newAuthMiddleware()))
+
+	if providerMetrics != nil {
+		options = append(options, stackitconfig.WithMiddleware(newAPIMetricsMiddleware(providerMetrics)))
+	}
+
+	// the timeout of an attempt starts once the attempt got past the rate limiter
+	if retryOptions.enabled() {
+		options = append(options, stackitconfig.WithMiddleware(newAttemptTimeoutMiddleware(requestTimeout)))
+	}
+
+	if rateLimitOptions.enabled() {
+		options = append(options, stackitconfig.WithMiddleware(newRateLimitMiddleware(rateLimitOptions)))
+	}
+
+	if retryOptions.enabled() {
+		options = append(options, stackitconfig.WithMiddleware(newRetryMiddleware(retryOptions)))
+	}
+
+	// the span of a call covers all of its retries and the time waiting for the rate limiter
+	options = append(options, stackitconfig.WithMiddleware(newTracingMiddleware(otel.GetTracerProvider())))
+
 	bearerTokenSet := len(bearerToken) > 0
 	keyPathSet := len(keyPath) > 0
 
@@ -39,7 +76,7 @@ func SetConfigOptions(baseURL, bearerToken, keyPath, tokenURL string) ([]stackit
 	if len(tokenURL) > 0 {
 		options = append(options, stackitconfig.WithTokenEndpoint(tokenURL))
 	}
-	options = append(options, stackitconfig.WithBackgroundTokenRefresh(context.Background()))
+	options = append(options, stackitconfig.WithBackgroundTokenRefresh(ctx))
 
 	return append(options, stackitconfig.WithServiceAccountKeyPath(keyPath)), nil
 }
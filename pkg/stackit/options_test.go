@@ -1,15 +1,20 @@
 package stackit
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
 )
 
 func TestMissingBaseURL(t *testing.T) {
 	t.Parallel()
 
-	options, err := SetConfigOptions("", "", "", "")
+	options, err := SetConfigOptions(context.Background(), "", "", "", "", RetryOptions{}, RateLimitOptions{}, nil)
 	assert.ErrorContains(t, err, "base-url")
 	assert.Nil(t, options)
 }
@@ -17,7 +22,7 @@ func TestMissingBaseURL(t *testing.T) {
 func TestBothAuthOptionsMissing(t *testing.T) {
 	t.Parallel()
 
-	options, err := SetConfigOptions("https://example.com", "", "", "")
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "", "", "", RetryOptions{}, RateLimitOptions{}, nil)
 	assert.ErrorContains(t, err, "auth-token or auth-key-path")
 	assert.Nil(t, options)
 }
@@ -25,7 +30,7 @@ func TestBothAuthOptionsMissing(t *testing.T) {
 func TestBothAuthOptionsSet(t *testing.T) {
 	t.Parallel()
 
-	options, err := SetConfigOptions("https://example.com", "token", "key/path", "")
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "token", "key/path", "", RetryOptions{}, RateLimitOptions{}, nil)
 	assert.ErrorContains(t, err, "auth-token or auth-key-path")
 	assert.Nil(t, options)
 }
@@ -33,23 +38,57 @@ func TestBothAuthOptionsSet(t *testing.T) {
 func TestBearerTokenSet(t *testing.T) {
 	t.Parallel()
 
-	options, err := SetConfigOptions("https://example.com", "token", "", "")
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "token", "", "", RetryOptions{}, RateLimitOptions{}, nil)
 	assert.NoError(t, err)
-	assert.Len(t, options, 3)
+	assert.Len(t, options, 5)
 }
 
 func TestKeyPathSet(t *testing.T) {
 	t.Parallel()
 
-	options, err := SetConfigOptions("https://example.com", "", "key/path", "")
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "", "key/path", "", RetryOptions{}, RateLimitOptions{}, nil)
 	assert.NoError(t, err)
-	assert.Len(t, options, 4)
+	assert.Len(t, options, 6)
 }
 
 func TestKeyPathAndURLSet(t *testing.T) {
 	t.Parallel()
 
-	options, err := SetConfigOptions("https://example.com", "", "key/path", "https://alternative.url.stackit.cloud/token")
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "", "key/path", "https://alternative.url.stackit.cloud/token", RetryOptions{}, RateLimitOptions{}, nil)
 	assert.NoError(t, err)
-	assert.Len(t, options, 5)
+	assert.Len(t, options, 7)
+}
+
+func TestRetryOptionsSet(t *testing.T) {
+	t.Parallel()
+
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "token", "", "", RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+	}, RateLimitOptions{}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, options, 7)
+}
+
+func TestRateLimitOptionsSet(t *testing.T) {
+	t.Parallel()
+
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "token", "", "", RetryOptions{}, RateLimitOptions{
+		RequestsPerSecond: 10,
+		Burst:             5,
+	}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, options, 6)
+}
+
+func TestProviderMetricsSet(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+
+	options, err := SetConfigOptions(context.Background(), "https://example.com", "token", "", "", RetryOptions{}, RateLimitOptions{},
+		mockmetrics.NewMockProviderMetrics(ctrl))
+	assert.NoError(t, err)
+	assert.Len(t, options, 6)
 }
@@ -0,0 +1,66 @@
+package stackit
+
+import (
+	"net/http"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"golang.org/x/time/rate"
+
+	"github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics"
+)
+
+// RateLimitOptions configures the client-side rate limit of requests to the STACKIT API.
+type RateLimitOptions struct {
+	// RequestsPerSecond is the sustained rate of requests. Values of 0 or below disable the rate limit.
+	RequestsPerSecond float64
+	// Burst is the number of requests which may be sent at once before the rate applies.
+	Burst int
+	// Metrics collects the time requests waited for the rate limiter.
+	Metrics metrics.RateLimiterMetrics
+}
+
+// enabled returns whether requests are rate limited at all.
+func (o RateLimitOptions) enabled() bool {
+	return o.RequestsPerSecond > 0
+}
+
+// newRateLimitMiddleware returns a middleware which delays requests according to a token bucket. All requests
+// of a client share the same bucket, no matter whether they list zones, fetch record sets or apply changes.
+func newRateLimitMiddleware(options RateLimitOptions) stackitconfig.Middleware {
+	limiter := rate.NewLimiter(rate.Limit(options.RequestsPerSecond), max(options.Burst, 1))
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &rateLimitTransport{
+			next:    next,
+			limiter: limiter,
+			metrics: options.Metrics,
+		}
+	}
+}
+
+// rateLimitTransport is an http.RoundTripper waiting for the rate limiter before sending a request.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter *rate.Limiter
+	metrics metrics.RateLimiterMetrics
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+
+	err := t.limiter.Wait(req.Context())
+	if t.metrics != nil {
+		t.metrics.CollectWaitDuration(time.Since(started).Seconds())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+// Unwrap returns the wrapped http.RoundTripper.
+func (t *rateLimitTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
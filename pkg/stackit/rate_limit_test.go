@@ -0,0 +1,128 @@
+package stackit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	mockmetrics "github.com/stackitcloud/external-dns-stackit-webhook/pkg/metrics/mock"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	mockMetrics := mockmetrics.NewMockRateLimiterMetrics(ctrl)
+	mockMetrics.EXPECT().CollectWaitDuration(gomock.Any()).Times(4)
+
+	client := &http.Client{
+		Transport: newRateLimitMiddleware(RateLimitOptions{
+			RequestsPerSecond: 20,
+			Burst:             2,
+			Metrics:           mockMetrics,
+		})(http.DefaultTransport),
+	}
+
+	started := time.Now()
+	for i := 0; i < 4; i++ {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+
+		resp, err := client.Do(req)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// the burst covers two requests, the remaining two wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(started), 90*time.Millisecond)
+}
+
+func TestRateLimitTransportContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := newRateLimitMiddleware(RateLimitOptions{
+		RequestsPerSecond: 0.001,
+		Burst:             1,
+	})(http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+	_, err = client.Do(req)
+	assert.Error(t, err)
+}
+
+func TestRateLimitTransportAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the middlewares are chained like in SetConfigOptions, the requests wait for the rate limiter far longer
+	// than the timeout of an attempt
+	transport := newRetryMiddleware(RetryOptions{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond})(
+		newRateLimitMiddleware(RateLimitOptions{RequestsPerSecond: 20, Burst: 1})(
+			newAttemptTimeoutMiddleware(20 * time.Millisecond)(http.DefaultTransport),
+		),
+	)
+	client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+			if err != nil {
+				errs <- err
+
+				return
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				errs <- err
+
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
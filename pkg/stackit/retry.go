@@ -0,0 +1,262 @@
+package stackit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+)
+
+// requestTimeout is the timeout of a single request to the STACKIT API.
+const requestTimeout = 10 * time.Second
+
+// RetryOptions configures how failed requests to the STACKIT API are retried.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of attempts per request including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the base wait time before the first retry. It doubles with every further retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait time between two attempts. A Retry-After header asking for a longer wait
+	// time ends the retries.
+	MaxBackoff time.Duration
+}
+
+// enabled returns whether requests are retried at all.
+func (o RetryOptions) enabled() bool {
+	return o.MaxAttempts > 1
+}
+
+// timeout returns the overall timeout of a request including all of its attempts and the wait time
+// between them. It only caps the request as a whole, every attempt is limited to requestTimeout by the
+// attempt timeout middleware.
+func (o RetryOptions) timeout() time.Duration {
+	if !o.enabled() {
+		return requestTimeout
+	}
+
+	attempts := time.Duration(o.MaxAttempts)
+
+	return requestTimeout*attempts + o.MaxBackoff*(attempts-1)
+}
+
+// newRetryMiddleware returns a middleware which retries requests that failed with 429 or a 5xx status code
+// using a jittered exponential backoff and honouring the Retry-After header. Requests with non-idempotent
+// methods are only retried on 429, since the API did not process them in that case. For every other
+// failure it is unknown whether they were applied.
+func newRetryMiddleware(options RetryOptions) stackitconfig.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &retryTransport{
+			next:    next,
+			options: options,
+			jitter:  equalJitter,
+		}
+	}
+}
+
+// retryTransport is an http.RoundTripper retrying failed requests.
+type retryTransport struct {
+	next    http.RoundTripper
+	options RetryOptions
+	jitter  func(time.Duration) time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt >= t.options.MaxAttempts || !shouldRetry(req, resp, err) {
+			return resp, err
+		}
+
+		wait, ok := t.backoff(attempt, resp)
+		if !ok || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// drain the body so that the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Unwrap returns the wrapped http.RoundTripper.
+func (t *retryTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
+
+// newAttemptTimeoutMiddleware returns a middleware which limits every single attempt of a request to the given
+// timeout, so that a hung attempt leaves time for the retries. It is placed inside the rate limit middleware, so
+// the time an attempt waits for the rate limiter does not count against its timeout.
+func newAttemptTimeoutMiddleware(timeout time.Duration) stackitconfig.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &attemptTimeoutTransport{
+			next:    next,
+			timeout: timeout,
+		}
+	}
+}
+
+// attemptTimeoutTransport is an http.RoundTripper limiting a single attempt to a timeout.
+type attemptTimeoutTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+// RoundTrip sends a single attempt limited to the timeout. The timeout covers reading the response body, so it
+// is only released once the body is closed.
+func (t *attemptTimeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// Unwrap returns the wrapped http.RoundTripper.
+func (t *attemptTimeoutTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
+
+// cancelBody is a response body releasing the context of its attempt once it is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
+
+// backoff returns the wait time before the next attempt. It returns false if the server asks for a longer
+// wait time than the maximum backoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return retryAfter, retryAfter <= t.options.MaxBackoff
+		}
+	}
+
+	wait := t.options.InitialBackoff
+	for i := 1; i < attempt && wait < t.options.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	return t.jitter(min(wait, t.options.MaxBackoff)), true
+}
+
+// rewindRequest returns the request to send for the given attempt. Retries need a fresh copy of the body.
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.Body == nil || req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	attemptReq := req.Clone(req.Context())
+	attemptReq.Body = body
+
+	return attemptReq, nil
+}
+
+// shouldRetry returns whether a request that ended with the given response or error may be sent again.
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// a failed authentication flow already retried getting the token itself
+		var authErr *AuthError
+		if errors.As(err, &authErr) {
+			return false
+		}
+
+		return isIdempotent(req.Method)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+// isIdempotent returns whether sending a request with the given method twice has the same effect as sending
+// it once. The partial updates of the provider always send the complete record set, so PATCH is safe to repeat.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either in seconds or as HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(time.Until(date), 0), true
+}
+
+// equalJitter returns a random duration between half of the given duration and the full duration.
+func equalJitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+
+	return half + rand.N(half+1) //nolint:gosec // jitter does not need a cryptographically secure random number
+}
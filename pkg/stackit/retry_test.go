@@ -0,0 +1,201 @@
+package stackit
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		method           string
+		statusCodes      []int
+		retryAfter       string
+		expectedStatus   int
+		expectedAttempts int32
+	}{
+		{"GET retried on 503", http.MethodGet, []int{503, 503, 200}, "", http.StatusOK, 3},
+		{"GET gives up after max attempts", http.MethodGet, []int{500, 502, 504, 200}, "", http.StatusGatewayTimeout, 3},
+		{"DELETE retried on 502", http.MethodDelete, []int{502, 200}, "", http.StatusOK, 2},
+		{"POST not retried on 503", http.MethodPost, []int{503, 200}, "", http.StatusServiceUnavailable, 1},
+		{"POST retried on 429", http.MethodPost, []int{429, 201}, "", http.StatusCreated, 2},
+		{"Retry-After within max backoff", http.MethodGet, []int{429, 200}, "0", http.StatusOK, 2},
+		{"Retry-After beyond max backoff", http.MethodGet, []int{429, 200}, "120", http.StatusTooManyRequests, 1},
+		{"Client error not retried", http.MethodGet, []int{400, 200}, "", http.StatusBadRequest, 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempt := attempts.Add(1)
+
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				if r.Method == http.MethodPost {
+					assert.Equal(t, "payload", string(body))
+				}
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statusCodes[attempt-1])
+			}))
+			defer server.Close()
+
+			client := getTestRetryClient()
+
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL, strings.NewReader("payload"))
+			assert.NoError(t, err)
+
+			resp, err := client.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tt.expectedAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestRetryTransportContextCanceled(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := &http.Client{
+		Transport: newRetryMiddleware(RetryOptions{
+			MaxAttempts:    3,
+			InitialBackoff: time.Hour,
+			MaxBackoff:     time.Hour,
+		})(http.DefaultTransport),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryTransportAttemptTimeout(t *testing.T) {
+	t.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt hangs until it is canceled
+		if attempts.Add(1) == 1 {
+			<-r.Context().Done()
+
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport := &retryTransport{
+		next:    newAttemptTimeoutMiddleware(50 * time.Millisecond)(http.DefaultTransport),
+		options: RetryOptions{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond},
+		jitter:  equalJitter,
+	}
+	client := &http.Client{Transport: transport, Timeout: 5 * time.Second}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	transport := &retryTransport{
+		options: RetryOptions{
+			MaxAttempts:    10,
+			InitialBackoff: time.Second,
+			MaxBackoff:     5 * time.Second,
+		},
+		jitter: func(d time.Duration) time.Duration { return d },
+	}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		got, ok := transport.backoff(i+1, nil)
+		assert.True(t, ok)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"Empty", "", 0, false},
+		{"Seconds", "30", 30 * time.Second, true},
+		{"Negative seconds", "-1", 0, false},
+		{"Date in the past", "Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+		{"Invalid", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := parseRetryAfter(tt.value)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 100; i++ {
+		got := equalJitter(10 * time.Second)
+		assert.GreaterOrEqual(t, got, 5*time.Second)
+		assert.LessOrEqual(t, got, 10*time.Second)
+	}
+}
+
+func getTestRetryClient() *http.Client {
+	transport := newRetryMiddleware(RetryOptions{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	})(http.DefaultTransport)
+
+	return &http.Client{Transport: transport}
+}
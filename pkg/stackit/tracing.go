@@ -0,0 +1,41 @@
+package stackit
+
+import (
+	"net/http"
+
+	stackitconfig "github.com/stackitcloud/stackit-sdk-go/core/config"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTracingMiddleware returns a middleware which creates a span for every request to the STACKIT API, named after
+// the DNS API operation, and propagates the trace context to the API.
+func newTracingMiddleware(tracerProvider trace.TracerProvider) stackitconfig.Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &tracingTransport{
+			next: next,
+			traced: otelhttp.NewTransport(
+				next,
+				otelhttp.WithTracerProvider(tracerProvider),
+				otelhttp.WithSpanNameFormatter(func(_ string, req *http.Request) string {
+					return "stackit." + apiOperation(req.Method, req.URL.Path)
+				}),
+			),
+		}
+	}
+}
+
+// tracingTransport is an http.RoundTripper tracing the requests it sends.
+type tracingTransport struct {
+	next   http.RoundTripper
+	traced http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.traced.RoundTrip(req)
+}
+
+// Unwrap returns the wrapped http.RoundTripper.
+func (t *tracingTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
@@ -0,0 +1,47 @@
+package stackit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingTransport(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	transport := newTracingMiddleware(tracerProvider)(http.DefaultTransport)
+	client := &http.Client{Transport: transport}
+
+	ctx, parent := tracerProvider.Tracer("test").Start(context.Background(), "parent")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, server.URL+"/v1/projects/p/zones/z/rrsets/r", nil)
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "stackit.PartialUpdateRecordSet", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+
+	// the transport is unwrapped to find the authentication flow
+	unwrapper, ok := transport.(interface{ Unwrap() http.RoundTripper })
+	assert.True(t, ok)
+	assert.Equal(t, http.DefaultTransport, unwrapper.Unwrap())
+}
@@ -0,0 +1,73 @@
+// Package tracing sets up OpenTelemetry tracing of the webhook.
+//
+// Spans are exported with OTLP over HTTP. Without an endpoint, the global tracer provider stays the no-op provider of
+// OpenTelemetry, so spans are not recorded at all.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+)
+
+// ServiceName is the name of the service the spans are reported for.
+const ServiceName = "external-dns-stackit-webhook"
+
+// Options configures the export of spans.
+type Options struct {
+	// Endpoint is the host and port of the OTLP HTTP receiver, e.g. localhost:4318. Tracing is disabled if it is empty.
+	Endpoint string
+	// Insecure disables TLS for the connection to the receiver.
+	Insecure bool
+	// SampleRatio is the ratio of traces which are sampled, between 0 and 1. Spans of a sampled parent are always
+	// sampled.
+	SampleRatio float64
+}
+
+// enabled returns whether spans are exported.
+func (o Options) enabled() bool {
+	return o.Endpoint != ""
+}
+
+// Setup sets the global tracer provider and propagator according to the options. The returned function flushes the
+// pending spans and stops the export, it must be called before the process exits.
+func Setup(ctx context.Context, options Options) (func(context.Context) error, error) {
+	if !options.enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporterOptions := []otlptracehttp.Option{otlptracehttp.WithEndpoint(options.Endpoint)}
+	if options.Insecure {
+		exporterOptions = append(exporterOptions, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(ctx, exporterOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("creating tracing resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return tracerProvider.Shutdown, nil
+}
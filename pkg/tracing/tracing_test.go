@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetupDisabled(t *testing.T) {
+	t.Parallel()
+
+	shutdown, err := Setup(context.Background(), Options{})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetupEnabled(t *testing.T) {
+	t.Parallel()
+
+	shutdown, err := Setup(context.Background(), Options{Endpoint: "localhost:4318", Insecure: true, SampleRatio: 1})
+	assert.NoError(t, err)
+	// no spans are pending, so nothing is sent to the endpoint
+	assert.NoError(t, shutdown(context.Background()))
+}
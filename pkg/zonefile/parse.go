@@ -0,0 +1,339 @@
+package zonefile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// classIN is the only supported class.
+const classIN = "IN"
+
+// nameFields are the fields of the record data which hold a domain name, by record type. Relative names in these
+// fields are qualified with the origin.
+var nameFields = map[string][]int{
+	"CNAME": {0},
+	"DNAME": {0},
+	"NS":    {0},
+	"PTR":   {0},
+	"MX":    {1},
+	"SRV":   {3},
+	"SOA":   {0, 1},
+}
+
+// line is a logical line of a zone file, which may span several physical lines within parentheses.
+type line struct {
+	number int
+	// inheritsOwner is set if the line starts with white space, so the record has the owner of the previous one.
+	inheritsOwner bool
+	tokens        []string
+}
+
+// Parse reads a zone in the master file format. The origin is used until the file sets another one with $ORIGIN.
+// Relative owner names and relative names in the data of CNAME, DNAME, NS, PTR, MX, SRV and SOA records are qualified
+// with the origin. The directive $INCLUDE and classes other than IN are not supported.
+func Parse(r io.Reader, origin string) (Zone, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return Zone{}, err
+	}
+
+	lines, err := splitLines(string(content))
+	if err != nil {
+		return Zone{}, err
+	}
+
+	zone := Zone{Origin: fqdn(origin)}
+	p := parser{origin: zone.Origin}
+
+	for _, l := range lines {
+		record, ok, err := p.parseLine(l)
+		if err != nil {
+			return Zone{}, fmt.Errorf("line %d: %w", l.number, err)
+		}
+
+		if ok {
+			zone.Records = append(zone.Records, record)
+		}
+	}
+
+	if p.defaultTTLSet {
+		zone.TTL = p.defaultTTL
+	}
+
+	return zone, nil
+}
+
+// parser holds the state carried from one line of a zone file to the next.
+type parser struct {
+	origin        string
+	defaultTTL    uint32
+	defaultTTLSet bool
+	lastOwner     string
+	lastTTL       uint32
+	lastTTLSet    bool
+}
+
+// parseLine parses a directive or a record. It returns false if the line holds no record.
+func (p *parser) parseLine(l line) (Record, bool, error) {
+	tokens := l.tokens
+
+	if !l.inheritsOwner && strings.HasPrefix(tokens[0], "$") {
+		return Record{}, false, p.parseDirective(tokens)
+	}
+
+	var record Record
+	if l.inheritsOwner {
+		if p.lastOwner == "" {
+			return Record{}, false, errors.New("missing owner name")
+		}
+		record.Name = p.lastOwner
+	} else {
+		record.Name = p.qualify(tokens[0])
+		tokens = tokens[1:]
+	}
+	p.lastOwner = record.Name
+
+	ttlSet := false
+	for len(tokens) > 0 {
+		if isClass(tokens[0]) {
+			if !strings.EqualFold(tokens[0], classIN) {
+				return Record{}, false, fmt.Errorf("unsupported class %q", tokens[0])
+			}
+			tokens = tokens[1:]
+
+			continue
+		}
+
+		ttl, err := parseTTL(tokens[0])
+		if err != nil || ttlSet {
+			break
+		}
+		record.TTL, ttlSet = ttl, true
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) < 2 {
+		return Record{}, false, errors.New("missing record type or data")
+	}
+
+	record.Type = strings.ToUpper(tokens[0])
+	data := tokens[1:]
+
+	switch {
+	case ttlSet:
+		p.lastTTL, p.lastTTLSet = record.TTL, true
+	case p.defaultTTLSet:
+		record.TTL = p.defaultTTL
+	case p.lastTTLSet:
+		record.TTL = p.lastTTL
+	default:
+		return Record{}, false, errors.New("missing TTL and no $TTL set")
+	}
+
+	for _, i := range nameFields[record.Type] {
+		if i < len(data) {
+			data[i] = p.qualify(data[i])
+		}
+	}
+	record.Data = strings.Join(data, " ")
+
+	return record, true, nil
+}
+
+// parseDirective applies the directives $ORIGIN and $TTL.
+func (p *parser) parseDirective(tokens []string) error {
+	directive := strings.ToUpper(tokens[0])
+	if len(tokens) != 2 {
+		return fmt.Errorf("%s: expected a single argument", directive)
+	}
+
+	switch directive {
+	case "$ORIGIN":
+		p.origin = p.qualify(tokens[1])
+	case "$TTL":
+		ttl, err := parseTTL(tokens[1])
+		if err != nil {
+			return fmt.Errorf("%s: %w", directive, err)
+		}
+		p.defaultTTL, p.defaultTTLSet = ttl, true
+	default:
+		return fmt.Errorf("unsupported directive %s", directive)
+	}
+
+	return nil
+}
+
+// qualify returns the fully qualified form of a name relative to the current origin.
+func (p *parser) qualify(name string) string {
+	switch {
+	case name == "@":
+		return p.origin
+	case strings.HasSuffix(name, "."):
+		return name
+	default:
+		return name + "." + p.origin
+	}
+}
+
+// splitLines splits the content of a zone file into logical lines of tokens. Comments are dropped, quoted strings are
+// kept as a single token including their quotes.
+func splitLines(content string) ([]line, error) {
+	var (
+		lines   []line
+		current line
+		token   strings.Builder
+		depth   int
+	)
+
+	number := 1
+	current.number = number
+
+	flushToken := func() {
+		if token.Len() > 0 {
+			current.tokens = append(current.tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '\n':
+			flushToken()
+			number++
+			if depth > 0 {
+				continue
+			}
+
+			if len(current.tokens) > 0 {
+				lines = append(lines, current)
+			}
+			current = line{number: number}
+		case ' ', '\t', '\r':
+			if depth == 0 && (i == 0 || content[i-1] == '\n') {
+				current.inheritsOwner = true
+			}
+			flushToken()
+		case ';':
+			flushToken()
+			for i+1 < len(content) && content[i+1] != '\n' {
+				i++
+			}
+		case '(':
+			flushToken()
+			depth++
+		case ')':
+			flushToken()
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unexpected closing parenthesis", number)
+			}
+			depth--
+		case '"':
+			end := closingQuote(content, i)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: missing closing quote", number)
+			}
+			token.WriteString(content[i : end+1])
+			i = end
+		case '\\':
+			token.WriteByte(c)
+			if i+1 < len(content) {
+				i++
+				token.WriteByte(content[i])
+			}
+		default:
+			token.WriteByte(c)
+		}
+	}
+
+	if depth > 0 {
+		return nil, fmt.Errorf("line %d: missing closing parenthesis", current.number)
+	}
+
+	flushToken()
+	if len(current.tokens) > 0 {
+		lines = append(lines, current)
+	}
+
+	return lines, nil
+}
+
+// closingQuote returns the index of the quote closing the quoted string starting at start, or -1 if there is none on
+// the same line.
+func closingQuote(content string, start int) int {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		case '\n':
+			return -1
+		}
+	}
+
+	return -1
+}
+
+// isClass returns whether the token is a DNS class.
+func isClass(token string) bool {
+	switch strings.ToUpper(token) {
+	case classIN, "CH", "HS", "CS":
+		return true
+	default:
+		return false
+	}
+}
+
+// parseTTL parses a TTL in seconds or with the units of BIND, e.g. 1h30m.
+func parseTTL(value string) (uint32, error) {
+	if value == "" {
+		return 0, errors.New("empty TTL")
+	}
+
+	if n, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return uint32(n), nil
+	}
+
+	var total, number uint64
+	digits := false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= '0' && c <= '9' {
+			number = number*10 + uint64(c-'0')
+			digits = true
+
+			continue
+		}
+
+		unit, ok := ttlUnits[c|0x20]
+		if !ok || !digits {
+			return 0, fmt.Errorf("invalid TTL %q", value)
+		}
+		total += number * unit
+		number, digits = 0, false
+	}
+
+	if digits {
+		return 0, fmt.Errorf("invalid TTL %q: missing unit", value)
+	}
+
+	if total > math.MaxUint32 {
+		return 0, fmt.Errorf("invalid TTL %q: out of range", value)
+	}
+
+	return uint32(total), nil
+}
+
+// ttlUnits are the units of a TTL in seconds.
+var ttlUnits = map[byte]uint64{
+	's': 1,
+	'm': 60,
+	'h': 60 * 60,
+	'd': 24 * 60 * 60,
+	'w': 7 * 24 * 60 * 60,
+}
@@ -0,0 +1,95 @@
+package zonefile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	content := `; exported from another provider
+$TTL 1h
+@	IN	SOA	ns1 hostmaster (
+		2024010101 ; serial
+		3600 600 1209600 60 )
+	IN	NS	ns1.provider.net.
+www	300	IN	A	192.0.2.1
+	IN	300	A	192.0.2.2
+mail	MX	10 mx
+txt	60	TXT	"v=spf1 include:_spf.example.com -all" "second; not a comment"
+$ORIGIN sub.example.com.
+api	CNAME	www.example.com.
+`
+
+	zone, err := Parse(strings.NewReader(content), "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, Zone{
+		Origin: "example.com.",
+		TTL:    3600,
+		Records: []Record{
+			{Name: "example.com.", TTL: 3600, Type: "SOA", Data: "ns1.example.com. hostmaster.example.com. 2024010101 3600 600 1209600 60"},
+			{Name: "example.com.", TTL: 3600, Type: "NS", Data: "ns1.provider.net."},
+			{Name: "www.example.com.", TTL: 300, Type: "A", Data: "192.0.2.1"},
+			{Name: "www.example.com.", TTL: 300, Type: "A", Data: "192.0.2.2"},
+			{Name: "mail.example.com.", TTL: 3600, Type: "MX", Data: "10 mx.example.com."},
+			{Name: "txt.example.com.", TTL: 60, Type: "TXT", Data: `"v=spf1 include:_spf.example.com -all" "second; not a comment"`},
+			{Name: "api.sub.example.com.", TTL: 3600, Type: "CNAME", Data: "www.example.com."},
+		},
+	}, zone)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"Missing TTL", "www IN A 192.0.2.1\n", "line 1: missing TTL and no $TTL set"},
+		{"Missing owner", "$TTL 60\n IN A 192.0.2.1\n", "line 2: missing owner name"},
+		{"Missing data", "$TTL 60\nwww IN A\n", "line 2: missing record type or data"},
+		{"Unsupported class", "$TTL 60\nwww CH A 192.0.2.1\n", `line 2: unsupported class "CH"`},
+		{"Unsupported directive", "$INCLUDE other.zone\n", "line 1: unsupported directive $INCLUDE"},
+		{"Invalid TTL", "$TTL 1x\n", `line 1: $TTL: invalid TTL "1x"`},
+		{"Missing closing quote", "$TTL 60\ntxt TXT \"foo\n", "line 2: missing closing quote"},
+		{"Missing closing parenthesis", "$TTL 60\n@ SOA ns1 hostmaster ( 1 2\n", "line 2: missing closing parenthesis"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Parse(strings.NewReader(tt.content), "example.com.")
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestWriteParseRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	zone := Zone{
+		Origin: "example.com.",
+		TTL:    3600,
+		Records: []Record{
+			{Name: "example.com.", TTL: 300, Type: "A", Data: "192.0.2.1"},
+			{Name: "www.example.com.", TTL: 60, Type: "CNAME", Data: "example.com.", Comment: "owner: team-a"},
+			{Name: "txt.example.com.", TTL: 60, Type: "TXT", Data: QuoteTXT(`say "hi"; bye`, strings.Repeat("a", 300))},
+		},
+	}
+
+	var b strings.Builder
+	assert.NoError(t, Write(&b, zone))
+
+	parsed, err := Parse(strings.NewReader(b.String()), "example.com.")
+	assert.NoError(t, err)
+
+	// comments are not read back
+	zone.Records[1].Comment = ""
+	assert.Equal(t, zone, parsed)
+}
@@ -0,0 +1,204 @@
+// Package zonefile reads and writes DNS zones in the master file format of RFC 1035, as used by BIND.
+//
+// Owner names inside the zone are written relative to its origin, with "@" for the apex. TXT data is always written
+// as quoted character strings of at most 255 bytes each. Records are read with fully qualified names.
+package zonefile
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxCharacterStringLength is the maximum length of a character string of RFC 1035.
+const maxCharacterStringLength = 255
+
+// Zone is a DNS zone with all of its records.
+type Zone struct {
+	// Origin is the fully qualified name of the zone.
+	Origin string `json:"origin"`
+	// TTL is the default TTL of the records in the zone.
+	TTL uint32 `json:"ttl"`
+	// Records are the records of the zone.
+	Records []Record `json:"records"`
+}
+
+// Record is a single resource record.
+type Record struct {
+	// Name is the fully qualified owner name.
+	Name string `json:"name"`
+	TTL  uint32 `json:"ttl"`
+	Type string `json:"type"`
+	// Data is the record data in presentation format, e.g. "10 mail.example.com." for an MX record or
+	// "\"v=spf1 -all\"" for a TXT record.
+	Data string `json:"data"`
+	// Comment is written as a comment after the record. It is optional.
+	Comment string `json:"comment,omitempty"`
+}
+
+// Write writes the zone in the master file format.
+func Write(w io.Writer, zone Zone) error {
+	origin := fqdn(zone.Origin)
+
+	if _, err := fmt.Fprintf(w, "$ORIGIN %s\n$TTL %d\n", origin, zone.TTL); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 1, '\t', 0)
+	for _, record := range zone.Records {
+		line := fmt.Sprintf("%s\t%d\tIN\t%s\t%s", relativeName(record.Name, origin), record.TTL, record.Type, record.Data)
+		if record.Comment != "" {
+			line += "\t; " + strings.ReplaceAll(record.Comment, "\n", " ")
+		}
+
+		if _, err := fmt.Fprintln(tw, line); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
+
+// QuoteTXT returns the TXT record data holding the given texts. Texts longer than 255 bytes are split into several
+// character strings, quotes and backslashes are escaped.
+func QuoteTXT(texts ...string) string {
+	var quoted []string
+	for _, text := range texts {
+		for len(text) > maxCharacterStringLength {
+			quoted = append(quoted, quoteCharacterString(text[:maxCharacterStringLength]))
+			text = text[maxCharacterStringLength:]
+		}
+		quoted = append(quoted, quoteCharacterString(text))
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// SplitTXT returns the character strings of TXT record data. Quoted strings may contain white space and escaped
+// characters, unquoted strings end at the next white space.
+func SplitTXT(data string) ([]string, error) {
+	var texts []string
+
+	for i := 0; i < len(data); {
+		switch data[i] {
+		case ' ', '\t':
+			i++
+		case '"':
+			text, n, err := readCharacterString(data[i+1:], true)
+			if err != nil {
+				return nil, err
+			}
+			texts = append(texts, text)
+			i += n + 1
+		default:
+			text, n, err := readCharacterString(data[i:], false)
+			if err != nil {
+				return nil, err
+			}
+			texts = append(texts, text)
+			i += n
+		}
+	}
+
+	return texts, nil
+}
+
+// readCharacterString reads a character string up to its closing quote, or up to the next white space if it is not
+// quoted. It returns the unescaped string and the number of bytes consumed.
+func readCharacterString(data string, quoted bool) (string, int, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case quoted && c == '"':
+			return b.String(), i + 1, nil
+		case !quoted && (c == ' ' || c == '\t'):
+			return b.String(), i, nil
+		case c == '\\':
+			if i+3 < len(data) && isDigits(data[i+1:i+4]) {
+				value, err := strconv.Atoi(data[i+1 : i+4])
+				if err != nil || value > 255 {
+					return "", 0, fmt.Errorf("invalid escape sequence %q", data[i:i+4])
+				}
+				b.WriteByte(byte(value))
+				i += 3
+
+				continue
+			}
+
+			if i+1 >= len(data) {
+				return "", 0, fmt.Errorf("unterminated escape sequence in %q", data)
+			}
+			i++
+			b.WriteByte(data[i])
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	if quoted {
+		return "", 0, fmt.Errorf("missing closing quote in %q", data)
+	}
+
+	return b.String(), len(data), nil
+}
+
+// quoteCharacterString quotes a character string, escaping quotes, backslashes and non-printable bytes.
+func quoteCharacterString(text string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// relativeName returns the name relative to the origin, "@" for the origin itself, or the fully qualified name if it
+// is outside of the origin.
+func relativeName(name, origin string) string {
+	name = fqdn(name)
+
+	switch {
+	case strings.EqualFold(name, origin):
+		return "@"
+	case len(name) > len(origin) && strings.EqualFold(name[len(name)-len(origin)-1:], "."+origin):
+		return name[:len(name)-len(origin)-1]
+	default:
+		return name
+	}
+}
+
+// fqdn returns the name with a trailing dot.
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+
+	return name + "."
+}
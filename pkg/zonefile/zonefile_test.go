@@ -0,0 +1,98 @@
+package zonefile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	err := Write(&b, Zone{
+		Origin: "example.com",
+		TTL:    3600,
+		Records: []Record{
+			{Name: "example.com.", TTL: 300, Type: "A", Data: "1.2.3.4"},
+			{Name: "www.example.com.", TTL: 60, Type: "CNAME", Data: "example.com.", Comment: "owner: team-a"},
+			{Name: "other.org.", TTL: 60, Type: "TXT", Data: `"foo"`},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "$ORIGIN example.com.\n$TTL 3600\n"+
+		"@\t\t300\tIN\tA\t1.2.3.4\n"+
+		"www\t\t60\tIN\tCNAME\texample.com.\t; owner: team-a\n"+
+		"other.org.\t60\tIN\tTXT\t\"foo\"\n", b.String())
+}
+
+func TestQuoteTXT(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{"Plain", []string{"v=spf1 -all"}, `"v=spf1 -all"`},
+		{"Multiple strings", []string{"foo", "bar"}, `"foo" "bar"`},
+		{"Escaped characters", []string{`say "hi" \ bye`}, `"say \"hi\" \\ bye"`},
+		{"Non-printable", []string{"a\tb"}, `"a\009b"`},
+		{"Long text", []string{strings.Repeat("a", 300)}, `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, QuoteTXT(tt.texts...))
+		})
+	}
+}
+
+func TestSplitTXT(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    []string
+		wantErr bool
+	}{
+		{"Quoted", `"v=spf1 -all"`, []string{"v=spf1 -all"}, false},
+		{"Multiple strings", `"foo" "bar"`, []string{"foo", "bar"}, false},
+		{"Unquoted", `foo bar`, []string{"foo", "bar"}, false},
+		{"Escaped characters", `"say \"hi\" \\ bye" "a\009b"`, []string{`say "hi" \ bye`, "a\tb"}, false},
+		{"Missing closing quote", `"foo`, nil, true},
+		{"Invalid escape sequence", `"\300"`, nil, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := SplitTXT(tt.data)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQuoteSplitTXTRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	texts := []string{`heritage=external-dns,external-dns/owner="default"`, "a\\b"}
+
+	got, err := SplitTXT(QuoteTXT(texts...))
+	assert.NoError(t, err)
+	assert.Equal(t, texts, got)
+}